@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
 	"github.com/kube-compose/kube-compose/internal/pkg/util"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -67,17 +71,79 @@ func getEnvIDFlag(cmd *cobra.Command) (string, error) {
 }
 
 func getNamespaceFlag(cmd *cobra.Command) (string, bool) {
-	var namespace string
-	var exists bool
-	if !cmd.Flags().Changed(namespaceFlagName) {
-		namespace, exists = envGetter(namespaceEnvVarName)
-		if !exists {
-			return "", false
+	return getStringFlagOrEnvVar(cmd, namespaceFlagName, namespaceEnvVarName)
+}
+
+// getStringFlagOrEnvVar returns the value of a flag if it is set, and otherwise the value of an environment variable.
+func getStringFlagOrEnvVar(cmd *cobra.Command, flagName, envVarName string) (string, bool) {
+	if !cmd.Flags().Changed(flagName) {
+		return envGetter(envVarName)
+	}
+	value, _ := cmd.Flags().GetString(flagName)
+	return value, true
+}
+
+// getBoolFlagOrEnvVar returns the value of a boolean flag if it is set, and otherwise the value of an environment variable.
+func getBoolFlagOrEnvVar(cmd *cobra.Command, flagName, envVarName string) (value, exists bool, err error) {
+	if cmd.Flags().Changed(flagName) {
+		value, _ = cmd.Flags().GetBool(flagName)
+		return value, true, nil
+	}
+	str, exists := envGetter(envVarName)
+	if !exists {
+		return false, false, nil
+	}
+	value, err = strconv.ParseBool(str)
+	if err != nil {
+		return false, false, fmt.Errorf("the environment variable %s must be a boolean", envVarName)
+	}
+	return value, true, nil
+}
+
+// setNamespace sets the namespace of the configuration. If each environment has its own namespace then the namespace is derived from the
+// environment ID, and setting the namespace explicitly is an error.
+func setNamespace(cmd *cobra.Command, cfg *config.Config) error {
+	namespacePerEnv, exists, err := getBoolFlagOrEnvVar(cmd, namespacePerEnvFlagName, namespacePerEnvEnvVarName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		cfg.NamespacePerEnvironment = namespacePerEnv
+	}
+	namespace, exists := getNamespaceFlag(cmd)
+	if !cfg.NamespacePerEnvironment {
+		if exists {
+			cfg.Namespace = namespace
 		}
-		return namespace, true
+		return nil
+	}
+	if exists {
+		return fmt.Errorf("the namespace cannot be set if each environment has its own namespace")
+	}
+	cfg.Namespace = k8smeta.GetEnvironmentNamespace(cfg)
+	return nil
+}
+
+// checkIgnoredKeys warns about keys of the docker compose files that are ignored, or returns an error in strict mode.
+func checkIgnoredKeys(cmd *cobra.Command, cfg *config.Config) error {
+	if strict, _ := cmd.Flags().GetBool(strictFlagName); strict {
+		cfg.Strict = true
+	}
+	return cfg.CheckIgnoredKeys()
+}
+
+// setNamingScheme sets the options that override the labels and name template of the configuration to the values of flags and
+// environment variables.
+func setNamingScheme(cmd *cobra.Command, opts *config.Options) {
+	if envLabel, exists := getStringFlagOrEnvVar(cmd, envLabelFlagName, envLabelEnvVarName); exists {
+		opts.EnvironmentLabel = &envLabel
+	}
+	if appLabel, exists := getStringFlagOrEnvVar(cmd, appLabelFlagName, appLabelEnvVarName); exists {
+		opts.AppLabel = &appLabel
+	}
+	if nameTemplate, exists := getStringFlagOrEnvVar(cmd, nameTemplateFlagName, nameTemplateEnvVarName); exists {
+		opts.NameTemplate = &nameTemplate
 	}
-	namespace, _ = cmd.Flags().GetString(namespaceFlagName)
-	return namespace, true
 }
 
 func getCommandConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
@@ -89,32 +155,69 @@ func getCommandConfig(cmd *cobra.Command, args []string) (*config.Config, error)
 	if err != nil {
 		return nil, err
 	}
-	cfg, err := config.New(file)
+	projectDirectory, _ := cmd.Flags().GetString(projectDirectoryFlagName)
+	opts := &config.Options{
+		Options: dockerComposeConfig.Options{
+			ProjectDirectory: projectDirectory,
+		},
+	}
+	setNamingScheme(cmd, opts)
+	cfg, err := config.NewWithOptions(file, opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if err := checkIgnoredKeys(cmd, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	if err := setFromKubeConfig(cfg); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	cfg.EnvironmentID = envID
-	if namespace, exists := getNamespaceFlag(cmd); exists {
-		cfg.Namespace = namespace
+	if err := setNamespace(cmd, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	if len(args) == 0 {
-		for _, service := range cfg.Services {
-			cfg.AddToFilter(service)
-		}
-	} else {
-		for _, arg := range args {
-			service := cfg.FindServiceByName(arg)
-			if service == nil {
-				fmt.Fprintf(os.Stderr, "no service named %#v exists\n", arg)
-				os.Exit(1)
-			}
-			cfg.AddToFilter(service)
-		}
+	if err := k8smeta.ValidateK8sNames(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg.Profiles = getProfiles(cmd)
+	if err := cfg.SetFilter(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 	return cfg, nil
 }
+
+// getProfiles returns the active profiles of the --profile flags, or of the environment variable COMPOSE_PROFILES (a comma separated
+// list) if no --profile flag is set.
+func getProfiles(cmd *cobra.Command) []string {
+	if cmd.Flags().Changed(profileFlagName) {
+		profiles, _ := cmd.Flags().GetStringArray(profileFlagName)
+		return profiles
+	}
+	var profiles []string
+	str, _ := envGetter(composeProfilesEnvVarName)
+	for _, profile := range strings.Split(str, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// setFilterWithoutDependencies sets the filter to match only the services named by the positional arguments, or all services if there are
+// no positional arguments. This is used by commands that should not affect the dependencies of services. It assumes that getCommandConfig
+// has validated the positional arguments.
+func setFilterWithoutDependencies(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		return
+	}
+	cfg.ClearFilter()
+	for _, arg := range args {
+		cfg.AddToFilterWithoutDependencies(cfg.FindServiceByName(arg))
+	}
+}
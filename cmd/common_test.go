@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
 	"github.com/spf13/cobra"
 )
 
@@ -113,3 +116,151 @@ func Test_GetNamespaceFlag_FlagSuccess(t *testing.T) {
 		}
 	})
 }
+
+func Test_SetNamingScheme_EnvAndFlagSuccess(t *testing.T) {
+	withMockedEnv(map[string]string{
+		envLabelEnvVarName:     "kube-compose/env",
+		nameTemplateEnvVarName: "{{.Name}}",
+	}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		_ = cmd.ParseFlags([]string{"--" + appLabelFlagName, "kube-compose/app"})
+		opts := &config.Options{}
+		setNamingScheme(cmd, opts)
+		if opts.AppLabel == nil || *opts.AppLabel != "kube-compose/app" || opts.EnvironmentLabel == nil ||
+			*opts.EnvironmentLabel != "kube-compose/env" || opts.NameTemplate == nil || *opts.NameTemplate != "{{.Name}}" {
+			t.Error(opts)
+		}
+	})
+}
+
+func Test_SetNamingScheme_NotSet(t *testing.T) {
+	withMockedEnv(map[string]string{}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		opts := &config.Options{}
+		setNamingScheme(cmd, opts)
+		if opts.AppLabel != nil || opts.EnvironmentLabel != nil || opts.NameTemplate != nil {
+			t.Error(opts)
+		}
+	})
+}
+
+func Test_SetNamespace_NamespacePerEnvSuccess(t *testing.T) {
+	withMockedEnv(map[string]string{
+		namespacePerEnvEnvVarName: "true",
+	}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		cfg := &config.Config{EnvironmentID: "123", Namespace: "default"}
+		err := setNamespace(cmd, cfg)
+		if err != nil {
+			t.Error(err)
+		} else if !cfg.NamespacePerEnvironment || cfg.Namespace != "kube-compose-123" {
+			t.Error(cfg)
+		}
+	})
+}
+
+func Test_SetNamespace_NamespacePerEnvAndNamespaceError(t *testing.T) {
+	withMockedEnv(map[string]string{}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		_ = cmd.ParseFlags([]string{"--" + namespacePerEnvFlagName, "--" + namespaceFlagName, "test"})
+		cfg := &config.Config{EnvironmentID: "123"}
+		err := setNamespace(cmd, cfg)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func Test_SetNamespace_InvalidEnvError(t *testing.T) {
+	withMockedEnv(map[string]string{
+		namespacePerEnvEnvVarName: "maybe",
+	}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		err := setNamespace(cmd, &config.Config{})
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func Test_SetNamespace_FlagOverridesXKubeCompose(t *testing.T) {
+	withMockedEnv(map[string]string{}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		_ = cmd.ParseFlags([]string{"--" + namespacePerEnvFlagName + "=false", "--" + namespaceFlagName, "test"})
+		cfg := &config.Config{NamespacePerEnvironment: true}
+		err := setNamespace(cmd, cfg)
+		if err != nil {
+			t.Error(err)
+		} else if cfg.NamespacePerEnvironment || cfg.Namespace != "test" {
+			t.Error(cfg)
+		}
+	})
+}
+
+func Test_SetFilterWithoutDependencies(t *testing.T) {
+	cfg := &config.Config{}
+	serviceA := cfg.AddService("a", &dockerComposeConfig.Service{})
+	serviceB := cfg.AddService("b", &dockerComposeConfig.Service{})
+	serviceA.DockerComposeService.DependsOn = map[*dockerComposeConfig.Service]dockerComposeConfig.ServiceHealthiness{
+		serviceB.DockerComposeService: dockerComposeConfig.ServiceStarted,
+	}
+	if err := cfg.AddToFilter(serviceA); err != nil {
+		t.Fatal(err)
+	}
+	setFilterWithoutDependencies(cfg, []string{"a"})
+	if !cfg.MatchesFilter(serviceA) || cfg.MatchesFilter(serviceB) {
+		t.Fail()
+	}
+	if err := cfg.AddToFilter(serviceB); err != nil {
+		t.Fatal(err)
+	}
+	setFilterWithoutDependencies(cfg, nil)
+	if !cfg.MatchesFilter(serviceB) {
+		t.Fail()
+	}
+}
+
+func Test_GetProfiles_Flag(t *testing.T) {
+	withMockedEnv(map[string]string{
+		"COMPOSE_PROFILES": "a",
+	}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		_ = cmd.ParseFlags([]string{"--" + profileFlagName, "b", "--" + profileFlagName, "c"})
+		if profiles := getProfiles(cmd); !reflect.DeepEqual(profiles, []string{"b", "c"}) {
+			t.Error(profiles)
+		}
+	})
+}
+
+func Test_GetProfiles_EnvVar(t *testing.T) {
+	withMockedEnv(map[string]string{
+		"COMPOSE_PROFILES": "a, b,",
+	}, func() {
+		cmd := &cobra.Command{}
+		setRootCommandFlags(cmd)
+		if profiles := getProfiles(cmd); !reflect.DeepEqual(profiles, []string{"a", "b"}) {
+			t.Error(profiles)
+		}
+	})
+}
+
+func Test_CheckIgnoredKeys_StrictFlagError(t *testing.T) {
+	cmd := &cobra.Command{}
+	setRootCommandFlags(cmd)
+	_ = cmd.ParseFlags([]string{"--" + strictFlagName})
+	cfg := &config.Config{
+		IgnoredKeys: []dockerComposeConfig.IgnoredKey{
+			{File: "/docker-compose.yml", Service: "a", Key: "tmpfs", Unsupported: true},
+		},
+	}
+	if err := checkIgnoredKeys(cmd, cfg); err == nil {
+		t.Fail()
+	}
+}
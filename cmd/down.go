@@ -16,6 +16,12 @@ func newDownCli() *cobra.Command {
 		Long: "destroy all pods and services",
 		RunE: downCommand,
 	}
+	downCmd.PersistentFlags().BoolP(keepNamespaceFlagName, "", false, "Do not delete the namespace of the environment if each "+
+		"environment has its own namespace")
+	downCmd.PersistentFlags().DurationP(timeoutFlagName, "", down.DefaultTimeout, "The maximum duration to wait for pods or the "+
+		"namespace to be deleted")
+	downCmd.PersistentFlags().BoolP(removeOrphansFlagName, "", false, "Remove pods and services of docker compose services that are no "+
+		"longer in the docker compose file")
 	return downCmd
 }
 
@@ -24,7 +30,17 @@ func downCommand(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	err = down.Run(cfg)
+	if len(args) == 0 {
+		// Delete the docker compose services of all profiles, so that no pods are left behind when profiles change.
+		for _, service := range cfg.Services {
+			cfg.AddToFilterWithoutDependencies(service)
+		}
+	}
+	opts := &down.Options{}
+	opts.KeepNamespace, _ = cmd.Flags().GetBool(keepNamespaceFlagName)
+	opts.Timeout, _ = cmd.Flags().GetDuration(timeoutFlagName)
+	opts.RemoveOrphans, _ = cmd.Flags().GetBool(removeOrphansFlagName)
+	err = down.Run(cfg, opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
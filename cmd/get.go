@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -43,7 +44,7 @@ func getCommand(cmd *cobra.Command, args []string) error {
 		}
 	}
 	service := cfg.FindServiceByName(args[0])
-	d, err := details.GetServiceDetails(cfg, service)
+	d, err := details.GetServiceDetails(context.Background(), cfg, service, nil)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kube-compose/kube-compose/internal/app/down"
+	"github.com/kube-compose/kube-compose/internal/app/up"
+	"github.com/spf13/cobra"
+)
+
+func newRestartCli() *cobra.Command {
+	var restartCmd = &cobra.Command{
+		Use:   "restart",
+		Short: "Restarts the pods of the specified docker compose services",
+		Long:  "deletes the pods of the specified docker compose services, waits until they have terminated, and starts them again",
+		RunE:  restartCommand,
+	}
+	restartCmd.PersistentFlags().DurationP(timeoutFlagName, "", down.DefaultTimeout, "The maximum duration to wait for pods to be "+
+		"deleted")
+	return restartCmd
+}
+
+func restartCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := getCommandConfig(cmd, args)
+	if err != nil {
+		return err
+	}
+	setFilterWithoutDependencies(cfg, args)
+	opts := &down.Options{}
+	opts.Timeout, _ = cmd.Flags().GetDuration(timeoutFlagName)
+	err = down.Stop(cfg, opts)
+	if err == nil {
+		err = up.Start(cfg, newStartOptions())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return nil
+}
@@ -7,12 +7,29 @@ import (
 )
 
 const (
-	envVarPrefix        = "KUBECOMPOSE_"
-	fileFlagName        = "file"
-	namespaceFlagName   = "namespace"
-	namespaceEnvVarName = envVarPrefix + "NAMESPACE"
-	envIDFlagName       = "env-id"
-	envIDEnvVarName     = envVarPrefix + "ENVID"
+	envVarPrefix              = "KUBECOMPOSE_"
+	fileFlagName              = "file"
+	projectDirectoryFlagName  = "project-directory"
+	namespaceFlagName         = "namespace"
+	namespaceEnvVarName       = envVarPrefix + "NAMESPACE"
+	envIDFlagName             = "env-id"
+	envIDEnvVarName           = envVarPrefix + "ENVID"
+	envLabelFlagName          = "env-label"
+	envLabelEnvVarName        = envVarPrefix + "ENVLABEL"
+	appLabelFlagName          = "app-label"
+	appLabelEnvVarName        = envVarPrefix + "APPLABEL"
+	nameTemplateFlagName      = "name-template"
+	nameTemplateEnvVarName    = envVarPrefix + "NAMETEMPLATE"
+	namespacePerEnvFlagName   = "namespace-per-env"
+	namespacePerEnvEnvVarName = envVarPrefix + "NAMESPACEPERENV"
+	keepNamespaceFlagName     = "keep-namespace"
+	timeoutFlagName           = "timeout"
+	removeOrphansFlagName     = "remove-orphans"
+	forceRecreateFlagName     = "force-recreate"
+	noRecreateFlagName        = "no-recreate"
+	strictFlagName            = "strict"
+	profileFlagName           = "profile"
+	composeProfilesEnvVarName = "COMPOSE_PROFILES"
 )
 
 func Execute() error {
@@ -22,16 +39,35 @@ func Execute() error {
 		Long:    "Environments on k8s made easy",
 		Version: "0.6.1",
 	}
-	rootCmd.AddCommand(newDownCli(), newUpCli(), newGetCli())
+	rootCmd.AddCommand(newDownCli(), newUpCli(), newGetCli(), newStopCli(), newStartCli(), newRestartCli())
 	setRootCommandFlags(rootCmd)
 	return rootCmd.Execute()
 }
 
 func setRootCommandFlags(rootCmd *cobra.Command) {
-	rootCmd.PersistentFlags().StringP(fileFlagName, "f", "", "Specify an alternate compose file")
+	rootCmd.PersistentFlags().StringP(fileFlagName, "f", "", "Specify an alternate compose file, or - to read the compose file from "+
+		"standard input")
+	rootCmd.PersistentFlags().StringP(projectDirectoryFlagName, "", "", "Specify an alternate working directory, which relative paths "+
+		"of the compose file are relative to (default: the directory of the compose file, or the current working directory if the "+
+		"compose file is read from standard input)")
 	rootCmd.PersistentFlags().StringP(namespaceFlagName, "n", "", fmt.Sprintf("namespace for environment. Can also be set via "+
 		"environment variable %sNAMESPACE", envVarPrefix))
 	rootCmd.PersistentFlags().StringP(envIDFlagName, "e", "", "used to isolate environments deployed to a shared namespace, "+
 		"by (1) using this value as a suffix of pod and service names and (2) using this value to isolate selectors. Either this flag or "+
 		fmt.Sprintf("the environment variable %sENVID must be set", envVarPrefix))
+	rootCmd.PersistentFlags().StringP(envLabelFlagName, "", "", fmt.Sprintf("the key of the label that selects the resources of an "+
+		"environment (default \"env\"). Can also be set via environment variable %s", envLabelEnvVarName))
+	rootCmd.PersistentFlags().StringP(appLabelFlagName, "", "", fmt.Sprintf("the key of the label that selects the resources of a "+
+		"docker compose service (default \"app\"). Can also be set via environment variable %s", appLabelEnvVarName))
+	rootCmd.PersistentFlags().StringP(nameTemplateFlagName, "", "", fmt.Sprintf("Go template of the names of pods and services, "+
+		"which can use .Name, .EnvironmentID and .Namespace (default \"{{.Name}}-{{.EnvironmentID}}\"). Can also be set via "+
+		"environment variable %s", nameTemplateEnvVarName))
+	rootCmd.PersistentFlags().BoolP(namespacePerEnvFlagName, "", false, fmt.Sprintf("when set, each environment has its own "+
+		"namespace that is derived from the environment ID, created by up and deleted by down. Can also be set via environment "+
+		"variable %s", namespacePerEnvEnvVarName))
+	rootCmd.PersistentFlags().BoolP(strictFlagName, "", false, "fail if the docker compose file has keys that are unknown or not "+
+		"supported by kube-compose, instead of printing warnings")
+	rootCmd.PersistentFlags().StringArrayP(profileFlagName, "", nil, fmt.Sprintf("activate a profile, so that the docker compose "+
+		"services of the profile are enabled. Can be repeated. Can also be set via environment variable %s as a comma separated list",
+		composeProfilesEnvVarName))
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kube-compose/kube-compose/internal/app/up"
+	"github.com/spf13/cobra"
+)
+
+func newStartCli() *cobra.Command {
+	var startCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Starts the stopped pods of the specified docker compose services",
+		Long:  "recreates pods that were deleted by the stop command in an order that respects depends_on, and waits until they are ready",
+		RunE:  startCommand,
+	}
+	return startCmd
+}
+
+func startCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := getCommandConfig(cmd, args)
+	if err != nil {
+		return err
+	}
+	err = up.Start(cfg, newStartOptions())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+func newStartOptions() *up.Options {
+	return &up.Options{
+		Context: context.Background(),
+		Detach:  true,
+	}
+}
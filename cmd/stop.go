@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kube-compose/kube-compose/internal/app/down"
+	"github.com/spf13/cobra"
+)
+
+func newStopCli() *cobra.Command {
+	var stopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Deletes the pods of the specified docker compose services, but keeps the Kubernetes services",
+		Long:  "stops pods so that they can be started again with the start command, without losing the cluster IPs of services",
+		RunE:  stopCommand,
+	}
+	stopCmd.PersistentFlags().DurationP(timeoutFlagName, "", down.DefaultTimeout, "The maximum duration to wait for pods to be deleted")
+	return stopCmd
+}
+
+func stopCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := getCommandConfig(cmd, args)
+	if err != nil {
+		return err
+	}
+	setFilterWithoutDependencies(cfg, args)
+	opts := &down.Options{}
+	opts.Timeout, _ = cmd.Flags().GetDuration(timeoutFlagName)
+	err = down.Stop(cfg, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return nil
+}
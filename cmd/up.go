@@ -19,6 +19,10 @@ func newUpCli() *cobra.Command {
 	upCmd.PersistentFlags().BoolP("detach", "d", false, "Detached mode: Run containers in the background")
 	upCmd.PersistentFlags().BoolP("run-as-user", "", false, "When set, the runAsUser/runAsGroup will be set for each pod based on the "+
 		"user of the pod's image and the \"user\" key of the pod's docker-compose service")
+	upCmd.PersistentFlags().BoolP(removeOrphansFlagName, "", false, "Remove pods and services of docker compose services that are no "+
+		"longer in the docker compose file")
+	upCmd.PersistentFlags().BoolP(forceRecreateFlagName, "", false, "Recreate pods even if their spec did not change")
+	upCmd.PersistentFlags().BoolP(noRecreateFlagName, "", false, "If pods already exist, do not recreate them even if their spec changed")
 	return upCmd
 }
 
@@ -31,6 +35,13 @@ func upCommand(cmd *cobra.Command, args []string) error {
 	opts.Context = context.Background()
 	opts.Detach, _ = cmd.Flags().GetBool("detach")
 	opts.RunAsUser, _ = cmd.Flags().GetBool("run-as-user")
+	opts.RemoveOrphans, _ = cmd.Flags().GetBool(removeOrphansFlagName)
+	opts.ForceRecreate, _ = cmd.Flags().GetBool(forceRecreateFlagName)
+	opts.NoRecreate, _ = cmd.Flags().GetBool(noRecreateFlagName)
+	if opts.ForceRecreate && opts.NoRecreate {
+		fmt.Fprintf(os.Stderr, "the flags --%s and --%s are incompatible\n", forceRecreateFlagName, noRecreateFlagName)
+		os.Exit(1)
+	}
 	err = up.Run(cfg, opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/kube-compose/kube-compose/internal/pkg/util"
 	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
@@ -16,13 +19,36 @@ type DockerRegistryClusterImageStorage struct {
 }
 
 type Service struct {
+	// Annotations and Labels are added to the Kubernetes resources of the docker compose service, in addition to the annotations and
+	// labels managed by kube-compose.
+	Annotations          map[string]string
 	DockerComposeService *dockerComposeConfig.Service
-	matchesFilter        bool
-	Name                 string
-	NameEscaped          string
-	Ports                []Port
+	// Headless is true if and only if the Kubernetes service of this docker compose service should not have a cluster IP.
+	Headless      bool
+	Labels        map[string]string
+	matchesFilter bool
+	Name          string
+	// NameEscaped is Name escaped using util.EscapeName, so that it can be used in the names of Kubernetes resources. It can be longer
+	// than a DNS label (see k8smeta.ShortenName).
+	NameEscaped string
+	// PodSpecOverrides are configured in the x-kube-compose of the docker compose service.
+	PodSpecOverrides PodSpecOverrides
+	// Ports are the unique ports and exposed ports of the docker compose service.
+	Ports []Port
 }
 
+// ServiceDiscovery determines how the pods of docker compose services resolve each other's hostnames.
+type ServiceDiscovery string
+
+const (
+	// ServiceDiscoveryHostAliases adds the cluster IPs of the Kubernetes services to the /etc/hosts file of each pod. This requires waiting
+	// until all cluster IPs have been assigned before any pod can be created.
+	ServiceDiscoveryHostAliases ServiceDiscovery = "host_aliases"
+	// ServiceDiscoveryDNS gives each pod a hostname and a subdomain that is backed by a headless Kubernetes service, and adds that
+	// subdomain to the DNS search domains of each pod. This allows pods to be created without waiting for cluster IPs.
+	ServiceDiscoveryDNS ServiceDiscovery = "dns"
+)
+
 type ClusterImageStorage struct {
 	Docker         *struct{}
 	DockerRegistry *DockerRegistryClusterImageStorage
@@ -31,13 +57,36 @@ type ClusterImageStorage struct {
 type Config struct {
 	dockerComposeServices map[string]*dockerComposeConfig.Service
 
-	// All Kubernetes resources are named with "-"+EnvironmentID as a suffix,
-	// and have an additional label "env="+EnvironmentID so that namespaces can be shared.
-	EnvironmentID       string
-	EnvironmentLabel    string
+	// Annotations and Labels are added to all Kubernetes resources, in addition to the annotations and labels managed by kube-compose.
+	Annotations map[string]string
+	// AppLabel is the key of the label that selects the resources of a docker compose service.
+	AppLabel string
+	// All Kubernetes resources are named with "-"+EnvironmentID as a suffix (see NameTemplate),
+	// and have an additional label EnvironmentLabel+"="+EnvironmentID so that namespaces can be shared.
+	EnvironmentID    string
+	EnvironmentLabel string
+	// EventHandler handles the warnings and progress that kube-compose reports. If it is nil then events are printed to standard output.
+	EventHandler        EventHandler
 	KubeConfig          *rest.Config
 	Namespace           string
 	ClusterImageStorage ClusterImageStorage
+	// ClusterDomain is the DNS domain of the Kubernetes cluster, and is empty if and only if DefaultClusterDomain should be used.
+	ClusterDomain string
+	// HeadlessServices is true if and only if docker compose services without ports should have a headless Kubernetes service.
+	HeadlessServices bool
+	// IgnoredKeys are the keys of the docker compose files that are ignored by kube-compose (see CheckIgnoredKeys).
+	IgnoredKeys []dockerComposeConfig.IgnoredKey
+	Labels      map[string]string
+	// NameTemplate determines the names of Kubernetes resources, and is nil if and only if DefaultNameTemplate should be used.
+	NameTemplate *template.Template
+	// NamespacePerEnvironment is true if and only if each environment has its own namespace, which is created by up and deleted by down.
+	NamespacePerEnvironment bool
+	// Profiles are the active profiles. Services with profiles are only enabled if one of their profiles is active (see IsEnabled).
+	Profiles []string
+	// ServiceDiscovery is empty if and only if ServiceDiscoveryHostAliases should be used.
+	ServiceDiscovery ServiceDiscovery
+	// Strict is true if and only if ignored keys of the docker compose files are errors instead of warnings.
+	Strict              bool
 	VolumeInitBaseImage *string
 
 	Services map[*dockerComposeConfig.Service]*Service
@@ -47,6 +96,9 @@ type Port struct {
 	Port int32
 	// one of "udp", "tcp" and "sctp"
 	Protocol string
+	// Name and AppProtocol are set by the long syntax of ports, and can be empty strings.
+	Name        string
+	AppProtocol string
 }
 
 func (cfg *Config) FindServiceByName(name string) *Service {
@@ -58,55 +110,181 @@ func (cfg *Config) FindService(dockerComposeService *dockerComposeConfig.Service
 }
 
 func New(file *string) (*Config, error) {
+	return NewWithOptions(file, nil)
+}
+
+// Options configure NewWithOptions.
+type Options struct {
+	// Options are passed to the loader of the docker compose files (see dockerComposeConfig.NewWithOptions).
+	dockerComposeConfig.Options
+	// EventHandler becomes the EventHandler of the returned Config, and also handles the warnings of loading the configuration.
+	EventHandler EventHandler
+	// AppLabel, EnvironmentLabel and NameTemplate override the naming scheme of the docker compose files if they are not nil. They are
+	// options rather than setters, because the labels of docker compose services are validated against the naming scheme while loading.
+	AppLabel         *string
+	EnvironmentLabel *string
+	NameTemplate     *string
+}
+
+// NewWithOptions is like New, but with options. opts can be nil.
+func NewWithOptions(file *string, opts *Options) (*Config, error) {
 	var files []string
 	if file != nil {
 		files = append(files, *file)
 	}
+	return newWithOptions(files, opts)
+}
+
+// NewFromFiles is like NewWithOptions, but loads and merges any number of docker compose files. If files is empty then the standard
+// docker compose files are loaded.
+func NewFromFiles(files []string, opts *Options) (*Config, error) {
+	return newWithOptions(files, opts)
+}
+
+func newWithOptions(files []string, opts *Options) (*Config, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
 	cfg := &Config{
-		EnvironmentLabel: "env",
+		AppLabel:         DefaultAppLabel,
+		EnvironmentLabel: DefaultEnvironmentLabel,
+		EventHandler:     opts.EventHandler,
 	}
-	dcCfg, err := dockerComposeConfig.New(files)
+	dcCfg, err := dockerComposeConfig.NewWithOptions(files, &opts.Options)
 	if err != nil {
 		return nil, err
 	}
 	cfg.dockerComposeServices = dcCfg.Services
+	cfg.IgnoredKeys = dcCfg.IgnoredKeys
+	err = checkServiceNamesUnique(dcCfg.Services)
+	if err != nil {
+		return nil, err
+	}
 	cfg.Services = map[*dockerComposeConfig.Service]*Service{}
 	for name, dcService := range dcCfg.Services {
-		if e := validation.IsDNS1123Subdomain(name); len(e) > 0 {
-			return nil, fmt.Errorf("sorry, we do not support the potentially valid docker compose service named %s: %s", name, e[0])
-		}
 		service := &Service{
 			DockerComposeService: dcService,
 			Name:                 name,
 			NameEscaped:          util.EscapeName(name),
 		}
-		for _, portBinding := range dcService.Ports {
-			service.Ports = append(service.Ports, Port{
-				Protocol: portBinding.Protocol,
-				Port:     portBinding.Internal,
-			})
+		service.Ports = appendPortsIfUnique(service.Ports, dcService.Ports)
+		service.Ports = appendPortsIfUnique(service.Ports, dcService.Expose)
+		err = validatePortNames(service)
+		if err != nil {
+			return nil, err
 		}
 		cfg.Services[dcService] = service
 	}
-	err = loadXKubeCompose(cfg, dcCfg.XProperties)
+	err = loadXKubeCompose(cfg, dcCfg.XProperties, opts)
 	if err != nil {
 		return nil, err
 	}
+	for _, service := range cfg.Services {
+		err = cfg.loadServiceXKubeCompose(service)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HeadlessServices {
+		for _, service := range cfg.Services {
+			service.Headless = len(service.Ports) == 0
+		}
+	}
 	return cfg, nil
 }
 
+// appendPortsIfUnique appends the internal ports of portBindings to ports, skipping ports that are already present. Duplicates are common
+// because docker compose allows an internal port to be published multiple times. The name and application protocol of a duplicate are
+// used if the port that is already present does not have them.
+func appendPortsIfUnique(ports []Port, portBindings []dockerComposeConfig.PortBinding) []Port {
+	for _, portBinding := range portBindings {
+		found := false
+		for i := range ports {
+			port := &ports[i]
+			if port.Port == portBinding.Internal && port.Protocol == portBinding.Protocol {
+				if port.Name == "" {
+					port.Name = portBinding.Name
+				}
+				if port.AppProtocol == "" {
+					port.AppProtocol = portBinding.AppProtocol
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			ports = append(ports, Port{
+				Protocol:    portBinding.Protocol,
+				Port:        portBinding.Internal,
+				Name:        portBinding.Name,
+				AppProtocol: portBinding.AppProtocol,
+			})
+		}
+	}
+	return ports
+}
+
+// checkServiceNamesUnique returns an error if two docker compose services have names that only differ in case, because hostnames are
+// case insensitive so that such services cannot be resolved by name.
+func checkServiceNamesUnique(dcServices map[string]*dockerComposeConfig.Service) error {
+	names := make([]string, 0, len(dcServices))
+	for name := range dcServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lowerNames := map[string]string{}
+	for _, name := range names {
+		lowerName := strings.ToLower(name)
+		if other, ok := lowerNames[lowerName]; ok {
+			return fmt.Errorf("docker compose services %s and %s have names that only differ in case, which is not supported because "+
+				"hostnames are case insensitive", other, name)
+		}
+		lowerNames[lowerName] = name
+	}
+	return nil
+}
+
+// validatePortNames validates the names of the ports of a docker compose service, which become the names of the ports of its Kubernetes
+// service.
+func validatePortNames(service *Service) error {
+	names := map[string]bool{}
+	for _, port := range service.Ports {
+		if port.Name == "" {
+			continue
+		}
+		if e := validation.IsValidPortName(port.Name); len(e) > 0 {
+			return fmt.Errorf("docker compose service %s has a port with invalid name %#v: %s", service.Name, port.Name, e[0])
+		}
+		if names[port.Name] {
+			return fmt.Errorf("docker compose service %s has multiple ports with name %#v", service.Name, port.Name)
+		}
+		names[port.Name] = true
+	}
+	return nil
+}
+
 type clusterImageStorage struct {
 	Type string  `mapdecode:"type"`
 	Host *string `mapdecode:"host"`
 }
 
-func loadXKubeCompose(cfg *Config, xProperties dockerComposeConfig.XProperties) error {
+func loadXKubeCompose(cfg *Config, xProperties dockerComposeConfig.XProperties, opts *Options) error {
 	var custom struct {
 		XKubeCompose struct {
-			ClusterImageStorage *clusterImageStorage `mapdecode:"cluster_image_storage"`
-			PushImages          *struct {
+			Annotations             map[string]string    `mapdecode:"annotations"`
+			AppLabel                *string              `mapdecode:"app_label"`
+			ClusterDomain           *string              `mapdecode:"cluster_domain"`
+			ClusterImageStorage     *clusterImageStorage `mapdecode:"cluster_image_storage"`
+			EnvironmentLabel        *string              `mapdecode:"environment_label"`
+			HeadlessServices        bool                 `mapdecode:"headless_services"`
+			Labels                  map[string]string    `mapdecode:"labels"`
+			NameTemplate            *string              `mapdecode:"name_template"`
+			NamespacePerEnvironment bool                 `mapdecode:"namespace_per_environment"`
+			PushImages              *struct {
 				DockerRegistry string `mapdecode:"docker_registry"`
 			} `mapdecode:"push_images"`
+			ServiceDiscovery    *string `mapdecode:"service_discovery"`
+			Strict              bool    `mapdecode:"strict"`
 			VolumeInitBaseImage *string `mapdecode:"volume_init_base_image"`
 		} `mapdecode:"x-kube-compose"`
 	}
@@ -124,13 +302,111 @@ func loadXKubeCompose(cfg *Config, xProperties dockerComposeConfig.XProperties)
 			return err
 		}
 	} else if custom.XKubeCompose.PushImages != nil {
-		fmt.Println("WARNING: a docker compose file has set \"x-kube-compose\".\"push_images\", but this functionality is deprecated. " +
-			"See https://github.com/kube-compose/kube-compose.")
+		cfg.EventHandler.Warnf("", "a docker compose file has set \"x-kube-compose\".\"push_images\", but this functionality is "+
+			"deprecated. See https://github.com/kube-compose/kube-compose.")
 		cfg.ClusterImageStorage.DockerRegistry = &DockerRegistryClusterImageStorage{
 			Host: custom.XKubeCompose.PushImages.DockerRegistry,
 		}
 	}
+	if custom.XKubeCompose.ClusterDomain != nil {
+		err = cfg.SetClusterDomain(*custom.XKubeCompose.ClusterDomain)
+		if err != nil {
+			return errors.Wrap(err, "error while parsing \"x-kube-compose\" of a docker compose file")
+		}
+	}
+	// The naming scheme is loaded before the labels, so that labels are validated against the keys of the labels that kube-compose
+	// manages.
+	err = loadNamingScheme(cfg, custom.XKubeCompose.AppLabel, custom.XKubeCompose.EnvironmentLabel, custom.XKubeCompose.NameTemplate, opts)
+	if err != nil {
+		return err
+	}
+	err = cfg.validateLabels(custom.XKubeCompose.Labels, "\"x-kube-compose\".\"labels\"")
+	if err != nil {
+		return err
+	}
+	err = validateAnnotations(custom.XKubeCompose.Annotations, "\"x-kube-compose\".\"annotations\"")
+	if err != nil {
+		return err
+	}
+	cfg.Annotations = custom.XKubeCompose.Annotations
+	cfg.HeadlessServices = custom.XKubeCompose.HeadlessServices
+	cfg.Labels = custom.XKubeCompose.Labels
+	cfg.NamespacePerEnvironment = custom.XKubeCompose.NamespacePerEnvironment
+	cfg.Strict = custom.XKubeCompose.Strict
 	cfg.VolumeInitBaseImage = custom.XKubeCompose.VolumeInitBaseImage
+	err = loadServiceDiscovery(cfg, custom.XKubeCompose.ServiceDiscovery)
+	if err != nil {
+		return err
+	}
+	if cfg.HeadlessServices && cfg.ServiceDiscovery != ServiceDiscoveryDNS {
+		// Headless services have no cluster IP that can be added to the host aliases of pods.
+		cfg.EventHandler.Warnf("", "a docker compose file has set \"x-kube-compose\".\"headless_services\", but docker compose services "+
+			"without ports can only be resolved by their names if \"x-kube-compose\".\"service_discovery\" is \"dns\"")
+	}
+	return nil
+}
+
+// xKubeComposeService is the schema of the x-kube-compose of a docker compose service.
+type xKubeComposeService struct {
+	Affinity           interface{}       `mapdecode:"affinity"`
+	Annotations        map[string]string `mapdecode:"annotations"`
+	ImagePullSecrets   []string          `mapdecode:"image_pull_secrets"`
+	Labels             map[string]string `mapdecode:"labels"`
+	NodeSelector       map[string]string `mapdecode:"node_selector"`
+	PodPatch           interface{}       `mapdecode:"pod_patch"`
+	PriorityClassName  string            `mapdecode:"priority_class_name"`
+	RuntimeClassName   *string           `mapdecode:"runtime_class_name"`
+	ServiceAccountName string            `mapdecode:"service_account_name"`
+	Tolerations        interface{}       `mapdecode:"tolerations"`
+}
+
+// loadServiceXKubeCompose loads the x-kube-compose of a docker compose service.
+func (cfg *Config) loadServiceXKubeCompose(service *Service) error {
+	var custom struct {
+		XKubeCompose xKubeComposeService `mapdecode:"x-kube-compose"`
+	}
+	err := mapdecode.Decode(&custom, service.DockerComposeService.XProperties, mapdecode.IgnoreUnused(true))
+	if err != nil {
+		return errors.Wrapf(err, "error while parsing \"x-kube-compose\" of docker compose service %s", service.Name)
+	}
+	path := fmt.Sprintf("\"x-kube-compose\" of docker compose service %s", service.Name)
+	err = cfg.validateLabels(custom.XKubeCompose.Labels, path)
+	if err != nil {
+		return err
+	}
+	err = validateAnnotations(custom.XKubeCompose.Annotations, path)
+	if err != nil {
+		return err
+	}
+	cfg.initServiceLabelsAndAnnotations(service, custom.XKubeCompose.Labels, custom.XKubeCompose.Annotations)
+	return loadPodSpecOverrides(service, &custom.XKubeCompose)
+}
+
+// loadNamingScheme sets the naming scheme of x-kube-compose, overridden by the options.
+func loadNamingScheme(cfg *Config, appLabel, environmentLabel, nameTemplate *string, opts *Options) error {
+	if opts.AppLabel != nil {
+		appLabel = opts.AppLabel
+	}
+	if opts.EnvironmentLabel != nil {
+		environmentLabel = opts.EnvironmentLabel
+	}
+	if opts.NameTemplate != nil {
+		nameTemplate = opts.NameTemplate
+	}
+	return cfg.setNamingScheme(appLabel, environmentLabel, nameTemplate)
+}
+
+func loadServiceDiscovery(cfg *Config, v *string) error {
+	if v == nil {
+		return nil
+	}
+	switch ServiceDiscovery(*v) {
+	case ServiceDiscoveryHostAliases, ServiceDiscoveryDNS:
+		cfg.ServiceDiscovery = ServiceDiscovery(*v)
+	default:
+		return fmt.Errorf("a docker compose file has an invalid value at \"x-kube-compose\".\"service_discovery\": value must be one " +
+			"of \"host_aliases\" and \"dns\"")
+	}
 	return nil
 }
 
@@ -196,9 +472,46 @@ func (cfg *Config) ClearFilter() {
 	}
 }
 
-// AddToFilter adds service and its (in)direct dependencies (based on depends_on) to the set of services matched by
-// the current filter.
-func (cfg *Config) AddToFilter(service *Service) {
+// AddToFilterWithoutDependencies adds service to the set of services matched by the current filter, but not its dependencies.
+func (cfg *Config) AddToFilterWithoutDependencies(service *Service) {
+	service.matchesFilter = true
+}
+
+// IsEnabled returns true if and only if service has no profiles or one of its profiles is active.
+func (cfg *Config) IsEnabled(service *Service) bool {
+	if len(service.DockerComposeService.Profiles) == 0 {
+		return true
+	}
+	for _, profile := range service.DockerComposeService.Profiles {
+		if containsString(cfg.Profiles, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivateProfiles activates the profiles of service. Like docker compose, the profiles of explicitly targeted services are activated,
+// so that their dependencies can be enabled by a common profile.
+func (cfg *Config) ActivateProfiles(service *Service) {
+	for _, profile := range service.DockerComposeService.Profiles {
+		if !containsString(cfg.Profiles, profile) {
+			cfg.Profiles = append(cfg.Profiles, profile)
+		}
+	}
+}
+
+func containsString(slice []string, s string) bool {
+	for _, element := range slice {
+		if element == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AddToFilter adds service and its (in)direct dependencies (based on depends_on) to the set of services matched by the current filter. An
+// error is returned if a dependency is not enabled by the active profiles. The profiles of service are not activated (see SetFilter).
+func (cfg *Config) AddToFilter(service *Service) error {
 	queue := []*Service{
 		service,
 	}
@@ -210,6 +523,10 @@ func (cfg *Config) AddToFilter(service *Service) {
 			service1.matchesFilter = true
 			for d := range service1.DockerComposeService.DependsOn {
 				service2 := cfg.FindService(d)
+				if !cfg.IsEnabled(service2) {
+					return fmt.Errorf("service %s was pulled in as a dependency of service %s but is not enabled by the active profiles. "+
+						"You may fix this by adding a common profile to %s and %s", service2.Name, service1.Name, service2.Name, service1.Name)
+				}
 				if n < len(queue) {
 					queue[n] = service2
 				} else {
@@ -219,4 +536,37 @@ func (cfg *Config) AddToFilter(service *Service) {
 			}
 		}
 	}
+	return nil
+}
+
+// SetFilter sets the filter to match the services with the specified names and their dependencies. If names is empty then the filter
+// matches the services that are enabled by the active profiles and their dependencies. The profiles of the named services are activated.
+func (cfg *Config) SetFilter(names []string) error {
+	if len(names) == 0 {
+		for _, service := range cfg.Services {
+			if cfg.IsEnabled(service) {
+				if err := cfg.AddToFilter(service); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	var services []*Service
+	for _, name := range names {
+		service := cfg.FindServiceByName(name)
+		if service == nil {
+			return fmt.Errorf("no service named %#v exists", name)
+		}
+		// The profiles of all named services are activated before dependencies are added, so that the order of the names does not
+		// matter.
+		cfg.ActivateProfiles(service)
+		services = append(services, service)
+	}
+	for _, service := range services {
+		if err := cfg.AddToFilter(service); err != nil {
+			return err
+		}
+	}
+	return nil
 }
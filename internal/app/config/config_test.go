@@ -7,6 +7,7 @@ import (
 	"github.com/kube-compose/kube-compose/internal/pkg/fs"
 	"github.com/kube-compose/kube-compose/internal/pkg/util"
 	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
 )
 
 func newTestConfig() *Config {
@@ -29,7 +30,9 @@ func TestAddToFilter(t *testing.T) {
 	cfg := newTestConfig()
 
 	// Since a depends on b, and b depends on c and d, we expect the result to contain all 4 apps.
-	cfg.AddToFilter(cfg.FindServiceByName("a"))
+	if err := cfg.AddToFilter(cfg.FindServiceByName("a")); err != nil {
+		t.Fatal(err)
+	}
 	resultContainsAppA := cfg.MatchesFilter(cfg.FindServiceByName("a"))
 	resultContainsAppB := cfg.MatchesFilter(cfg.FindServiceByName("b"))
 	resultContainsAppC := cfg.MatchesFilter(cfg.FindServiceByName("c"))
@@ -39,9 +42,58 @@ func TestAddToFilter(t *testing.T) {
 	}
 }
 
+func TestIsEnabled(t *testing.T) {
+	cfg := newTestConfig()
+	serviceA := cfg.FindServiceByName("a")
+	if !cfg.IsEnabled(serviceA) {
+		t.Fail()
+	}
+	serviceA.DockerComposeService.Profiles = []string{"debug"}
+	if cfg.IsEnabled(serviceA) {
+		t.Fail()
+	}
+	cfg.Profiles = []string{"debug"}
+	if !cfg.IsEnabled(serviceA) {
+		t.Fail()
+	}
+}
+
+func TestAddToFilter_DependencyNotEnabled(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.FindServiceByName("b").DockerComposeService.Profiles = []string{"stubs"}
+	err := cfg.AddToFilter(cfg.FindServiceByName("a"))
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestSetFilter_CommonProfile(t *testing.T) {
+	cfg := newTestConfig()
+	// a is explicitly targeted, so its profile is activated and enables b.
+	cfg.FindServiceByName("a").DockerComposeService.Profiles = []string{"debug"}
+	cfg.FindServiceByName("b").DockerComposeService.Profiles = []string{"debug"}
+	err := cfg.SetFilter([]string{"a"})
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(cfg.Profiles, []string{"debug"}) || !cfg.MatchesFilter(cfg.FindServiceByName("b")) {
+		t.Fail()
+	}
+}
+
+func TestAddToFilterWithoutDependencies(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.AddToFilterWithoutDependencies(cfg.FindServiceByName("a"))
+	if !cfg.MatchesFilter(cfg.FindServiceByName("a")) || cfg.MatchesFilter(cfg.FindServiceByName("b")) {
+		t.Fail()
+	}
+}
+
 func TestClearFilter(t *testing.T) {
 	cfg := newTestConfig()
-	cfg.AddToFilter(cfg.FindServiceByName("a"))
+	if err := cfg.AddToFilter(cfg.FindServiceByName("a")); err != nil {
+		t.Fatal(err)
+	}
 	cfg.ClearFilter()
 	for _, service := range cfg.Services {
 		if service.matchesFilter {
@@ -297,3 +349,740 @@ x-kube-compose:
 		}
 	})
 }
+
+func TestNew_ServiceDiscoverySuccess(t *testing.T) {
+	file := "/servicediscoverysuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  service_discovery: dns
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+		} else if c.ServiceDiscovery != ServiceDiscoveryDNS {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_ServiceDiscoveryInvalid(t *testing.T) {
+	file := "/servicediscoveryinvalid"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  service_discovery: invalid
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_ClusterDomainSuccess(t *testing.T) {
+	file := "/clusterdomainsuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  cluster_domain: example.org
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+		} else if c.ClusterDomain != "example.org" {
+			t.Error(c.ClusterDomain)
+		}
+	})
+}
+
+func TestNew_ClusterDomainInvalid(t *testing.T) {
+	file := "/clusterdomaininvalid"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  cluster_domain: Example_Org
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_ServiceNameNotDNSSubdomain(t *testing.T) {
+	file := "/servicenamenotdnssubdomain"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  My_Service:
+    image: ubuntu:latest
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		service := c.FindServiceByName("My_Service")
+		if service == nil || service.NameEscaped != util.EscapeName("My_Service") {
+			t.Error(service)
+		}
+	})
+}
+
+func TestNew_ServiceNamesDifferInCaseError(t *testing.T) {
+	file := "/servicenamesdifferincase"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  Web:
+    image: ubuntu:latest
+  web:
+    image: ubuntu:latest
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_PortsAndExposeSuccess(t *testing.T) {
+	file := "/portsandexposesuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    ports:
+    - '8080:80'
+    - target: 80
+      published: 8081
+      name: web
+      app_protocol: http
+    expose: [80, '3000/udp']
+  b:
+    image: ubuntu:latest
+x-kube-compose:
+  headless_services: true
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		serviceA := c.FindServiceByName("a")
+		expected := []Port{
+			{
+				Port:        80,
+				Protocol:    "tcp",
+				Name:        "web",
+				AppProtocol: "http",
+			},
+			{
+				Port:     3000,
+				Protocol: "udp",
+			},
+		}
+		if !reflect.DeepEqual(serviceA.Ports, expected) {
+			t.Error(serviceA.Ports)
+		}
+		if serviceA.Headless || !c.FindServiceByName("b").Headless {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_HeadlessServicesWarning(t *testing.T) {
+	testCases := map[string]int{
+		"host_aliases": 1,
+		"dns":          0,
+	}
+	for serviceDiscovery, expected := range testCases {
+		file := "/headlessserviceswarning"
+		withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+			file: {
+				Content: []byte(`version: '2.4'
+x-kube-compose:
+  headless_services: true
+  service_discovery: ` + serviceDiscovery + `
+`),
+			},
+		}), func() {
+			var events []*Event
+			_, err := NewWithOptions(&file, &Options{
+				EventHandler: func(event *Event) {
+					events = append(events, event)
+				},
+			})
+			if err != nil {
+				t.Error(err)
+			} else if len(events) != expected {
+				t.Error(serviceDiscovery, events)
+			}
+		})
+	}
+}
+
+func TestNew_PortNameErrors(t *testing.T) {
+	testCases := []string{
+		`- target: 80
+      name: Not_A_Port_Name`,
+		`- target: 80
+      name: web
+    - target: 81
+      name: web`,
+	}
+	for _, ports := range testCases {
+		file := "/portnameerrors"
+		withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+			file: {
+				Content: []byte(`version: '3.8'
+services:
+  a:
+    image: ubuntu:latest
+    ports:
+    ` + ports + `
+`),
+			},
+		}), func() {
+			_, err := New(&file)
+			if err == nil {
+				t.Error(ports)
+			}
+		})
+	}
+}
+
+func TestNew_LabelsAndAnnotationsSuccess(t *testing.T) {
+	file := "/labelsandannotationssuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    labels:
+      com.example.team: myteam
+      com.example.description: Accounting webapp
+      app: ignored
+      cost-center: shared
+    x-kube-compose:
+      labels:
+        cost-center: "1234"
+      annotations:
+        prometheus.io/scrape: "true"
+x-kube-compose:
+  labels:
+    cost-center: shared
+    environment: test
+  annotations:
+    sidecar.istio.io/inject: "false"
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expectedLabels := map[string]string{
+			"com.example.team": "myteam",
+			"cost-center":      "1234",
+			"environment":      "test",
+		}
+		serviceA := c.FindServiceByName("a")
+		if !reflect.DeepEqual(serviceA.Labels, expectedLabels) {
+			t.Error(serviceA.Labels)
+		}
+		expectedAnnotations := map[string]string{
+			"com.example.description": "Accounting webapp",
+			"prometheus.io/scrape":    "true",
+			"sidecar.istio.io/inject": "false",
+		}
+		if !reflect.DeepEqual(serviceA.Annotations, expectedAnnotations) {
+			t.Error(serviceA.Annotations)
+		}
+		if !reflect.DeepEqual(c.Labels, map[string]string{"cost-center": "shared", "environment": "test"}) {
+			t.Error(c.Labels)
+		}
+	})
+}
+
+func TestNew_LabelsProtected(t *testing.T) {
+	file := "/labelsprotected"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  labels:
+    env: myenv
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_ServiceLabelsInvalid(t *testing.T) {
+	file := "/servicelabelsinvalid"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    x-kube-compose:
+      labels:
+        team: not a valid label value
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_AnnotationsInvalid(t *testing.T) {
+	file := "/annotationsinvalid"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  annotations:
+    "not a valid key": "true"
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_PodSpecOverridesSuccess(t *testing.T) {
+	file := "/podspecoverridessuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    x-kube-compose:
+      node_selector:
+        disktype: ssd
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: ci
+        effect: NoSchedule
+      affinity:
+        nodeAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            nodeSelectorTerms:
+            - matchExpressions:
+              - key: zone
+                operator: In
+                values: [a]
+      service_account_name: ci
+      priority_class_name: low
+      image_pull_secrets: [registry]
+      runtime_class_name: gvisor
+      pod_patch:
+        spec:
+          containers:
+          - name: a
+            resources:
+              limits:
+                cpu: "1"
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		overrides := c.FindServiceByName("a").PodSpecOverrides
+		if !reflect.DeepEqual(overrides.NodeSelector, map[string]string{"disktype": "ssd"}) {
+			t.Error(overrides.NodeSelector)
+		}
+		expectedTolerations := []v1.Toleration{
+			{
+				Key:      "dedicated",
+				Operator: v1.TolerationOpEqual,
+				Value:    "ci",
+				Effect:   v1.TaintEffectNoSchedule,
+			},
+		}
+		if !reflect.DeepEqual(overrides.Tolerations, expectedTolerations) {
+			t.Error(overrides.Tolerations)
+		}
+		if overrides.Affinity == nil || overrides.Affinity.NodeAffinity == nil {
+			t.Error(overrides.Affinity)
+		}
+		if overrides.ServiceAccountName != "ci" || overrides.PriorityClassName != "low" {
+			t.Error(overrides)
+		}
+		if !reflect.DeepEqual(overrides.ImagePullSecrets, []v1.LocalObjectReference{{Name: "registry"}}) {
+			t.Error(overrides.ImagePullSecrets)
+		}
+		if overrides.RuntimeClassName == nil || *overrides.RuntimeClassName != "gvisor" {
+			t.Error(overrides.RuntimeClassName)
+		}
+		if string(overrides.PodPatch) != `{"spec":{"containers":[{"name":"a","resources":{"limits":{"cpu":"1"}}}]}}` {
+			t.Error(string(overrides.PodPatch))
+		}
+	})
+}
+
+func TestNew_PodSpecOverridesInvalidTolerations(t *testing.T) {
+	file := "/podspecoverridesinvalidtolerations"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    x-kube-compose:
+      tolerations:
+      - unknownField: true
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_PodSpecOverridesInvalidPodPatch(t *testing.T) {
+	file := "/podspecoverridesinvalidpodpatch"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    x-kube-compose:
+      pod_patch: not an object
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_NamingSchemeSuccess(t *testing.T) {
+	file := "/namingschemesuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  app_label: kube-compose/app
+  environment_label: kube-compose/env
+  name_template: '{{.EnvironmentID}}-{{.Name}}'
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if c.AppLabel != "kube-compose/app" || c.EnvironmentLabel != "kube-compose/env" || c.NameTemplate == nil {
+			t.Error(c)
+		}
+	})
+}
+
+func TestNew_NamingSchemeInvalid(t *testing.T) {
+	file := "/namingschemeinvalid"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  environment_label: app
+`),
+		},
+	}), func() {
+		_, err := New(&file)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestSetNameTemplate_Errors(t *testing.T) {
+	cfg := &Config{}
+	for _, text := range []string{"{{.Name", "{{.Unknown}}", "{{if false}}x{{end}}"} {
+		err := cfg.SetNameTemplate(text)
+		if err == nil {
+			t.Error(text)
+		}
+	}
+	if cfg.NameTemplate != nil {
+		t.Fail()
+	}
+}
+
+func TestSetNamingScheme_Invalid(t *testing.T) {
+	cfg := &Config{
+		AppLabel:         DefaultAppLabel,
+		EnvironmentLabel: DefaultEnvironmentLabel,
+	}
+	for _, key := range []string{"", "not a label", DefaultEnvironmentLabel} {
+		err := cfg.setNamingScheme(&key, nil, nil)
+		if err == nil {
+			t.Error(key)
+		}
+	}
+	if cfg.AppLabel != DefaultAppLabel {
+		t.Error(cfg.AppLabel)
+	}
+}
+
+func TestSetNamingScheme_Swap(t *testing.T) {
+	cfg := &Config{
+		AppLabel:         DefaultAppLabel,
+		EnvironmentLabel: DefaultEnvironmentLabel,
+	}
+	appLabel := DefaultEnvironmentLabel
+	environmentLabel := DefaultAppLabel
+	err := cfg.setNamingScheme(&appLabel, &environmentLabel, nil)
+	if err != nil {
+		t.Error(err)
+	} else if cfg.AppLabel != DefaultEnvironmentLabel || cfg.EnvironmentLabel != DefaultAppLabel {
+		t.Error(cfg)
+	}
+}
+
+func TestNewWithOptions_NamingSchemeLabels(t *testing.T) {
+	file := "/namingschemelabels"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    image: ubuntu:latest
+    labels:
+      env: myenv
+      kube-compose/env: ignored
+`),
+		},
+	}), func() {
+		environmentLabel := "kube-compose/env"
+		var events []*Event
+		c, err := NewWithOptions(&file, &Options{
+			EnvironmentLabel: &environmentLabel,
+			EventHandler: func(event *Event) {
+				events = append(events, event)
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// The default environment label is not managed by kube-compose once the environment label is overridden.
+		expected := map[string]string{
+			"env": "myenv",
+		}
+		if labels := c.FindServiceByName("a").Labels; !reflect.DeepEqual(labels, expected) {
+			t.Error(labels)
+		}
+		if len(events) != 1 {
+			t.Error(events)
+		}
+	})
+}
+
+func TestNewWithOptions_NamingSchemeLabelsProtected(t *testing.T) {
+	file := "/namingschemelabelsprotected"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+x-kube-compose:
+  labels:
+    kube-compose/app: myapp
+`),
+		},
+	}), func() {
+		appLabel := "kube-compose/app"
+		_, err := NewWithOptions(&file, &Options{
+			AppLabel: &appLabel,
+		})
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_NamespacePerEnvironmentSuccess(t *testing.T) {
+	file := "/namespaceperenvironmentsuccess"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    image: ubuntu:latest
+x-kube-compose:
+  namespace_per_environment: true
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+		} else if !c.NamespacePerEnvironment {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_StrictIgnoredKeysError(t *testing.T) {
+	file := "/strictignoredkeyserror"
+	withMockFS2(fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		file: {
+			Content: []byte(`version: '2.4'
+services:
+  a:
+    image: ubuntu:latest
+    tmpfs: /tmp
+x-kube-compose:
+  strict: true
+`),
+		},
+	}), func() {
+		c, err := New(&file)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !c.Strict || len(c.IgnoredKeys) != 1 {
+			t.Error(c.Strict, c.IgnoredKeys)
+		}
+		if err = c.CheckIgnoredKeys(); err == nil {
+			t.Fail()
+		}
+		c.Strict = false
+		if err = c.CheckIgnoredKeys(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSetFilter_Profiles(t *testing.T) {
+	cfg := &Config{}
+	serviceA := cfg.AddService("a", &dockerComposeConfig.Service{})
+	serviceB := cfg.AddService("b", &dockerComposeConfig.Service{
+		Profiles: []string{"debug"},
+	})
+	err := cfg.SetFilter(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if !cfg.MatchesFilter(serviceA) || cfg.MatchesFilter(serviceB) {
+		t.Fail()
+	}
+	cfg.ClearFilter()
+	err = cfg.SetFilter([]string{"b"})
+	if err != nil {
+		t.Error(err)
+	}
+	if cfg.MatchesFilter(serviceA) || !cfg.MatchesFilter(serviceB) {
+		t.Fail()
+	}
+}
+
+func TestSetFilter_ProfilesNotActivatedImplicitly(t *testing.T) {
+	// Services are visited in the random order of a map, so run the test a number of times.
+	for i := 0; i < 20; i++ {
+		cfg := &Config{
+			Profiles: []string{"debug"},
+		}
+		serviceA := cfg.AddService("a", &dockerComposeConfig.Service{
+			Profiles: []string{"debug", "extra"},
+		})
+		serviceB := cfg.AddService("b", &dockerComposeConfig.Service{
+			Profiles: []string{"extra"},
+		})
+		err := cfg.SetFilter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.MatchesFilter(serviceA) || cfg.MatchesFilter(serviceB) || !reflect.DeepEqual(cfg.Profiles, []string{"debug"}) {
+			t.Fatal(cfg.Profiles)
+		}
+	}
+}
+
+func TestSetFilter_NoSuchService(t *testing.T) {
+	cfg := &Config{}
+	err := cfg.SetFilter([]string{"a"})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestCheckIgnoredKeys_EventHandler(t *testing.T) {
+	var events []*Event
+	cfg := &Config{
+		EventHandler: func(event *Event) {
+			events = append(events, event)
+		},
+		IgnoredKeys: []dockerComposeConfig.IgnoredKey{
+			{File: "/docker-compose.yml", Service: "a", Key: "tmpfs", Unsupported: true},
+		},
+	}
+	if err := cfg.CheckIgnoredKeys(); err != nil {
+		t.Error(err)
+	}
+	expected := []*Event{
+		{
+			Type:    EventWarning,
+			Message: "file \"/docker-compose.yml\": service a has unsupported key tmpfs, ignoring the key",
+		},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Error(events)
+	}
+}
+
+func TestEventString(t *testing.T) {
+	testCases := map[string]*Event{
+		"app a: WARNING: w": {Type: EventWarning, Service: "a", Message: "w"},
+		"WARNING: w":        {Type: EventWarning, Message: "w"},
+		"app a: i":          {Type: EventInfo, Service: "a", Message: "i"},
+		"i":                 {Type: EventInfo, Message: "i"},
+		"a| l":              {Type: EventLog, Service: "a", Message: "l"},
+	}
+	for expected, event := range testCases {
+		if s := event.String(); s != expected {
+			t.Errorf("%s: %s", expected, s)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	// EventInfo reports progress, for example that a pod was created.
+	EventInfo EventType = "info"
+	// EventWarning reports that (part of) the configuration is ignored or not fully supported.
+	EventWarning EventType = "warning"
+	// EventLog is a line of the logs of a container.
+	EventLog EventType = "log"
+)
+
+// Event is something that kube-compose reports to the user while loading a configuration or running a command.
+type Event struct {
+	Type EventType
+	// Service is the name of the docker compose service the event is about, and is empty if the event is not about a single service.
+	Service string
+	Message string
+}
+
+func (e *Event) String() string {
+	switch {
+	case e.Type == EventLog:
+		return fmt.Sprintf("%s| %s", e.Service, e.Message)
+	case e.Type == EventWarning && e.Service != "":
+		return fmt.Sprintf("app %s: WARNING: %s", e.Service, e.Message)
+	case e.Type == EventWarning:
+		return "WARNING: " + e.Message
+	case e.Service != "":
+		return fmt.Sprintf("app %s: %s", e.Service, e.Message)
+	}
+	return e.Message
+}
+
+// EventHandler handles the events of kube-compose. It can be called concurrently by multiple goroutines. The nil EventHandler prints each
+// event to standard output (see PrintEvent).
+type EventHandler func(event *Event)
+
+// PrintEvent prints an event to standard output.
+func PrintEvent(event *Event) {
+	fmt.Println(event.String())
+}
+
+// Handle passes an event to h, or prints the event if h is nil.
+func (h EventHandler) Handle(event *Event) {
+	if h == nil {
+		PrintEvent(event)
+		return
+	}
+	h(event)
+}
+
+// Infof handles an event of type EventInfo. service can be empty.
+func (h EventHandler) Infof(service, format string, args ...interface{}) {
+	h.Handle(&Event{
+		Type:    EventInfo,
+		Service: service,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Warnf handles an event of type EventWarning. service can be empty.
+func (h EventHandler) Warnf(service, format string, args ...interface{}) {
+	h.Handle(&Event{
+		Type:    EventWarning,
+		Service: service,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
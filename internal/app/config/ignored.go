@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckIgnoredKeys reports a warning for each key of the docker compose files that is ignored by kube-compose. If Strict is true then an
+// error is returned instead.
+func (cfg *Config) CheckIgnoredKeys() error {
+	if len(cfg.IgnoredKeys) == 0 {
+		return nil
+	}
+	if !cfg.Strict {
+		for i := 0; i < len(cfg.IgnoredKeys); i++ {
+			cfg.EventHandler.Warnf("", "%s, ignoring the key", &cfg.IgnoredKeys[i])
+		}
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("docker compose files have keys that are not supported by kube-compose:")
+	for i := 0; i < len(cfg.IgnoredKeys); i++ {
+		sb.WriteString("\n  ")
+		sb.WriteString(cfg.IgnoredKeys[i].String())
+	}
+	return fmt.Errorf("%s", sb.String())
+}
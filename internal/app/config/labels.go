@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// isProtectedLabel returns true if and only if a label is managed by kube-compose, because it is used in selectors.
+func (cfg *Config) isProtectedLabel(key string) bool {
+	return key == cfg.AppLabel || key == cfg.EnvironmentLabel
+}
+
+// validateLabels validates labels that are configured in x-kube-compose. The path is used in error messages.
+func (cfg *Config) validateLabels(labels map[string]string, path string) error {
+	for key, value := range labels {
+		if cfg.isProtectedLabel(key) {
+			return fmt.Errorf("%s has a label %s that is managed by kube-compose", path, key)
+		}
+		if e := validation.IsQualifiedName(key); len(e) > 0 {
+			return fmt.Errorf("%s has an invalid label key %#v: %s", path, key, e[0])
+		}
+		if e := validation.IsValidLabelValue(value); len(e) > 0 {
+			return fmt.Errorf("%s has an invalid value for label %s: %s", path, key, e[0])
+		}
+	}
+	return nil
+}
+
+// validateAnnotations validates annotations that are configured in x-kube-compose. The path is used in error messages.
+func validateAnnotations(annotations map[string]string, path string) error {
+	for key := range annotations {
+		if e := validation.IsQualifiedName(strings.ToLower(key)); len(e) > 0 {
+			return fmt.Errorf("%s has an invalid annotation key %#v: %s", path, key, e[0])
+		}
+	}
+	return nil
+}
+
+// initServiceLabelsAndAnnotations sets the labels and annotations of the Kubernetes resources of a docker compose service. These are the
+// labels and annotations of x-kube-compose, followed by the labels of the docker compose service, followed by the labels and annotations
+// of the x-kube-compose of the docker compose service. Docker labels are often not valid Kubernetes labels (for example, if their values
+// contain spaces), so docker labels become annotations if they are not valid Kubernetes labels.
+func (cfg *Config) initServiceLabelsAndAnnotations(service *Service, xLabels, xAnnotations map[string]string) {
+	service.Labels = copyStringMap(cfg.Labels)
+	service.Annotations = copyStringMap(cfg.Annotations)
+	for key, value := range service.DockerComposeService.Labels {
+		switch {
+		case cfg.isProtectedLabel(key):
+			cfg.EventHandler.Warnf("", "docker compose service %s has a label %s that is managed by kube-compose, ignoring the label",
+				service.Name, key)
+		case len(validation.IsQualifiedName(key)) == 0 && len(validation.IsValidLabelValue(value)) == 0:
+			service.Labels[key] = value
+		case len(validation.IsQualifiedName(strings.ToLower(key))) == 0:
+			service.Annotations[key] = value
+		default:
+			cfg.EventHandler.Warnf("", "docker compose service %s has a label %#v that is neither a valid Kubernetes label nor a valid "+
+				"Kubernetes annotation, ignoring the label", service.Name, key)
+		}
+	}
+	for key, value := range xLabels {
+		service.Labels[key] = value
+	}
+	for key, value := range xAnnotations {
+		service.Annotations[key] = value
+	}
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DefaultClusterDomain is the default DNS domain of the Kubernetes cluster.
+	DefaultClusterDomain = "cluster.local"
+	// DefaultAppLabel is the default key of the label that selects the resources of a docker compose service.
+	DefaultAppLabel = "app"
+	// DefaultEnvironmentLabel is the default key of the label that selects the resources of an environment.
+	DefaultEnvironmentLabel = "env"
+	// DefaultNameTemplate is the default template of the names of Kubernetes resources.
+	DefaultNameTemplate = "{{.Name}}-{{.EnvironmentID}}"
+)
+
+// NameTemplateData is the data of the template that determines the names of Kubernetes resources.
+type NameTemplateData struct {
+	// Name is the escaped name of the docker compose service.
+	Name          string
+	EnvironmentID string
+	Namespace     string
+}
+
+// SetClusterDomain sets the DNS domain of the Kubernetes cluster, which is used to build fully qualified domain names of Kubernetes
+// services.
+func (cfg *Config) SetClusterDomain(domain string) error {
+	if e := validation.IsDNS1123Subdomain(domain); len(e) > 0 {
+		return fmt.Errorf("the cluster domain %#v is not a valid DNS subdomain: %s", domain, e[0])
+	}
+	cfg.ClusterDomain = domain
+	return nil
+}
+
+// setNamingScheme sets the keys of the labels and the name template that are not nil. Both label keys are validated before either is set,
+// so that the keys can be swapped.
+func (cfg *Config) setNamingScheme(appLabel, environmentLabel, nameTemplate *string) error {
+	appLabelKey := cfg.AppLabel
+	if appLabel != nil {
+		if err := validateLabelKey(*appLabel, "app label"); err != nil {
+			return err
+		}
+		appLabelKey = *appLabel
+	}
+	environmentLabelKey := cfg.EnvironmentLabel
+	if environmentLabel != nil {
+		if err := validateLabelKey(*environmentLabel, "environment label"); err != nil {
+			return err
+		}
+		environmentLabelKey = *environmentLabel
+	}
+	if appLabelKey == environmentLabelKey {
+		return fmt.Errorf("the app label and the environment label must be different, but both are %#v", appLabelKey)
+	}
+	cfg.AppLabel = appLabelKey
+	cfg.EnvironmentLabel = environmentLabelKey
+	if nameTemplate != nil {
+		return cfg.SetNameTemplate(*nameTemplate)
+	}
+	return nil
+}
+
+func validateLabelKey(key, what string) error {
+	if e := validation.IsQualifiedName(key); len(e) > 0 {
+		return fmt.Errorf("the %s %#v is not a valid label key: %s", what, key, e[0])
+	}
+	return nil
+}
+
+// SetNameTemplate parses and sets the template that determines the names of Kubernetes resources. The template is executed with a
+// NameTemplateData value.
+func (cfg *Config) SetNameTemplate(text string) error {
+	nameTemplate, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("the name template %#v is invalid: %v", text, err)
+	}
+	// Execute the template once, so that errors in the template are detected before any resources are created.
+	var buffer bytes.Buffer
+	err = nameTemplate.Execute(&buffer, &NameTemplateData{
+		Name:          "name",
+		EnvironmentID: "envid",
+		Namespace:     "namespace",
+	})
+	if err != nil {
+		return fmt.Errorf("the name template %#v is invalid: %v", text, err)
+	}
+	if buffer.Len() == 0 {
+		return fmt.Errorf("the name template %#v is invalid: names must not be empty", text)
+	}
+	cfg.NameTemplate = nameTemplate
+	return nil
+}
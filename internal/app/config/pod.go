@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// PodSpecOverrides are the fields of the pod spec of a docker compose service that are configured in the x-kube-compose of that docker
+// compose service. These allow cluster specifics to be configured without changing the docker compose service.
+type PodSpecOverrides struct {
+	Affinity           *v1.Affinity
+	ImagePullSecrets   []v1.LocalObjectReference
+	NodeSelector       map[string]string
+	PriorityClassName  string
+	RuntimeClassName   *string
+	ServiceAccountName string
+	Tolerations        []v1.Toleration
+	// PodPatch is a JSON strategic merge patch that is applied to the pod of the docker compose service, or nil if there is no such patch.
+	PodPatch []byte
+}
+
+// loadPodSpecOverrides converts the pod spec fields of the x-kube-compose of a docker compose service. Values of Kubernetes types (like
+// affinity) are converted by encoding them as JSON, so that they have the same syntax as in Kubernetes manifests.
+func loadPodSpecOverrides(service *Service, x *xKubeComposeService) error {
+	overrides := &service.PodSpecOverrides
+	if x.Affinity != nil {
+		overrides.Affinity = &v1.Affinity{}
+		err := decodeKubernetesValue(x.Affinity, overrides.Affinity)
+		if err != nil {
+			return podSpecOverridesError(service, "affinity", err)
+		}
+	}
+	for _, name := range x.ImagePullSecrets {
+		overrides.ImagePullSecrets = append(overrides.ImagePullSecrets, v1.LocalObjectReference{
+			Name: name,
+		})
+	}
+	overrides.NodeSelector = x.NodeSelector
+	overrides.PriorityClassName = x.PriorityClassName
+	overrides.RuntimeClassName = x.RuntimeClassName
+	overrides.ServiceAccountName = x.ServiceAccountName
+	if x.Tolerations != nil {
+		err := decodeKubernetesValue(x.Tolerations, &overrides.Tolerations)
+		if err != nil {
+			return podSpecOverridesError(service, "tolerations", err)
+		}
+	}
+	if x.PodPatch != nil {
+		podPatch, err := toJSON(x.PodPatch)
+		if err != nil {
+			return podSpecOverridesError(service, "pod_patch", err)
+		}
+		// Apply the patch to an empty pod, so that invalid patches are detected before any resources are created.
+		_, err = strategicpatch.StrategicMergePatch([]byte("{}"), podPatch, v1.Pod{})
+		if err != nil {
+			return podSpecOverridesError(service, "pod_patch", err)
+		}
+		overrides.PodPatch = podPatch
+	}
+	return nil
+}
+
+func podSpecOverridesError(service *Service, key string, err error) error {
+	return fmt.Errorf("docker compose service %s has an invalid value at \"x-kube-compose\".%#v: %v", service.Name, key, err)
+}
+
+// decodeKubernetesValue decodes a value that was loaded from YAML into a Kubernetes type, rejecting unknown fields.
+func decodeKubernetesValue(value, into interface{}) error {
+	data, err := toJSON(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(into)
+}
+
+// toJSON encodes a value that was loaded from YAML as JSON. This requires maps with interface{} keys to be converted, because these are
+// not supported by encoding/json.
+func toJSON(value interface{}) ([]byte, error) {
+	value, err := toJSONCompatible(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func toJSONCompatible(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("maps must only have string keys, but got key %#v", key)
+			}
+			var err error
+			result[keyString], err = toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			result[i], err = toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,39 @@
+package down
+
+import (
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getAnchor gets the ConfigMap that anchors the environment. The anchor is ignored if it does not exist or was not created by kube-compose.
+func (d *downRunner) getAnchor() error {
+	anchor, err := d.k8sClientset.CoreV1().ConfigMaps(d.cfg.Namespace).Get(k8smeta.GetAnchorName(d.cfg), metav1.GetOptions{})
+	switch {
+	case k8sError.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	case k8smeta.IsManagedObjectOfEnvironment(d.cfg, &anchor.ObjectMeta):
+		d.anchor = anchor
+	}
+	return nil
+}
+
+// deleteAnchor deletes the ConfigMap that anchors the environment. Because foreground cascading deletion is used, the garbage collector
+// deletes any remaining objects of the environment before the anchor.
+func (d *downRunner) deleteAnchor() error {
+	if d.anchor == nil {
+		return nil
+	}
+	deleteOptions := newForegroundDeleteOptions()
+	deleteOptions.Preconditions = &metav1.Preconditions{
+		UID: &d.anchor.UID,
+	}
+	err := d.k8sClientset.CoreV1().ConfigMaps(d.cfg.Namespace).Delete(d.anchor.Name, deleteOptions)
+	if err != nil && !k8sError.IsNotFound(err) {
+		return err
+	}
+	d.cfg.EventHandler.Infof("", "deleted ConfigMap %s", d.anchor.Name)
+	return nil
+}
@@ -1,66 +1,141 @@
 package down
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/kube-compose/kube-compose/internal/app/config"
 	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	clientV1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
+const pollInterval = time.Second
+
+// DefaultTimeout is the default maximum duration that down waits for pods or the namespace to be deleted.
+const DefaultTimeout = 5 * time.Minute
+
 type deleter func(name string, options *metav1.DeleteOptions) error
 
 type lister func(listOptions metav1.ListOptions) ([]*metav1.ObjectMeta, error)
 
 type downRunner struct {
+	anchor           *v1.ConfigMap
 	cfg              *config.Config
-	k8sClientset     *kubernetes.Clientset
+	opts             *Options
+	k8sClientset     kubernetes.Interface
 	k8sServiceClient clientV1.ServiceInterface
 	k8sPodClient     clientV1.PodInterface
+	keptOrphans      bool
 }
 
 func (d *downRunner) initKubernetesClientset() error {
-	k8sClientset, err := kubernetes.NewForConfig(d.cfg.KubeConfig)
-	if err != nil {
-		return err
+	if d.opts.KubernetesClient != nil {
+		d.k8sClientset = d.opts.KubernetesClient
+	} else {
+		k8sClientset, err := kubernetes.NewForConfig(d.cfg.KubeConfig)
+		if err != nil {
+			return err
+		}
+		d.k8sClientset = k8sClientset
 	}
-	d.k8sClientset = k8sClientset
 	d.k8sServiceClient = d.k8sClientset.CoreV1().Services(d.cfg.Namespace)
 	d.k8sPodClient = d.k8sClientset.CoreV1().Pods(d.cfg.Namespace)
 	return nil
 }
 
-func (d *downRunner) deleteCommon(kind string, lister lister, deleter deleter) (bool, error) {
+// isOwned returns true if and only if an object was created by kube-compose for the environment. Objects are owned if they reference
+// the anchor of the environment. Objects created by older versions of kube-compose do not have owner references, and are owned if they
+// have the annotation of a docker compose service.
+func (d *downRunner) isOwned(objectMeta *metav1.ObjectMeta) bool {
+	if d.anchor != nil && k8smeta.IsOwnedBy(objectMeta, d.anchor.UID) {
+		return true
+	}
+	return len(objectMeta.OwnerReferences) == 0 && k8smeta.FindFromObjectMeta(d.cfg, objectMeta) != nil
+}
+
+func (d *downRunner) isOrphan(objectMeta *metav1.ObjectMeta) bool {
+	var anchorUID types.UID
+	if d.anchor != nil {
+		anchorUID = d.anchor.UID
+	}
+	return k8smeta.IsOrphan(d.cfg, objectMeta, anchorUID)
+}
+
+func newForegroundDeleteOptions() *metav1.DeleteOptions {
+	propagationPolicy := metav1.DeletePropagationForeground
+	return &metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+}
+
+func (d *downRunner) timeout() time.Duration {
+	if d.opts.Timeout > 0 {
+		return d.opts.Timeout
+	}
+	return DefaultTimeout
+}
+
+// poll calls condition until it returns true or an error, the timeout expires or the context of the options is done.
+func (d *downRunner) poll(condition wait.ConditionFunc) error {
+	ctx := d.opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+	return wait.PollImmediateUntil(pollInterval, condition, ctx.Done())
+}
+
+// deleteCommon deletes the objects that are owned by the environment and that match the filter. It returns the names of the deleted
+// objects and whether all owned objects were deleted.
+func (d *downRunner) deleteCommon(kind string, lister lister, deleter deleter) ([]string, bool, error) {
 	listOptions := metav1.ListOptions{
-		LabelSelector: d.cfg.EnvironmentLabel + "=" + d.cfg.EnvironmentID,
+		LabelSelector: k8smeta.GetEnvironmentSelector(d.cfg),
 	}
 	list, err := lister(listOptions)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
-	deleteOptions := &metav1.DeleteOptions{}
+	deleteOptions := newForegroundDeleteOptions()
+	var deleted []string
 	deletedAll := true
 	for _, item := range list {
+		if d.isOrphan(item) {
+			if !d.opts.RemoveOrphans {
+				d.cfg.EventHandler.Warnf("", "found orphan %s %s of service %s that is not in the docker compose file, use "+
+					"--remove-orphans to delete it", kind, item.Name, item.Annotations[k8smeta.AnnotationName])
+				d.keptOrphans = true
+				continue
+			}
+		} else if !d.isOwned(item) {
+			continue
+		}
 		composeService := k8smeta.FindFromObjectMeta(d.cfg, item)
 		if composeService == nil || d.cfg.MatchesFilter(composeService) {
 			err = deleter(item.Name, deleteOptions)
-			if err != nil {
-				return false, err
+			if err != nil && !k8sError.IsNotFound(err) {
+				return nil, false, err
 			}
-			fmt.Printf("deleted %s %s\n", kind, item.Name)
+			deleted = append(deleted, item.Name)
+			d.cfg.EventHandler.Infof("", "deleted %s %s", kind, item.Name)
 		} else {
 			deletedAll = false
 		}
 	}
-	return deletedAll, nil
+	return deleted, deletedAll, nil
 }
 
 // Linter reports code duplication amongst deleteServices and deletePods. Although this is true, deduplicating would require the use of
 // generics, so we choose to nolint.
 // nolint
-func (d *downRunner) deleteServices() (bool, error) {
+func (d *downRunner) deleteServices() ([]string, bool, error) {
 	lister := func(listOptions metav1.ListOptions) ([]*metav1.ObjectMeta, error) {
 		serviceList, err := d.k8sServiceClient.List(listOptions)
 		if err != nil {
@@ -78,7 +153,7 @@ func (d *downRunner) deleteServices() (bool, error) {
 // Linter reports code duplication amongst deleteServices and deletePods. Although this is true, deduplicating would require the use of
 // generics, so we choose to nolint.
 // nolint
-func (d *downRunner) deletePods() (bool, error) {
+func (d *downRunner) deletePods() ([]string, bool, error) {
 	lister := func(listOptions metav1.ListOptions) ([]*metav1.ObjectMeta, error) {
 		podList, err := d.k8sPodClient.List(listOptions)
 		if err != nil {
@@ -93,13 +168,57 @@ func (d *downRunner) deletePods() (bool, error) {
 	return d.deleteCommon("Pod", lister, d.k8sPodClient.Delete)
 }
 
+// waitForPodsDeleted waits until the pods with the specified names no longer exist.
+func (d *downRunner) waitForPodsDeleted(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	d.cfg.EventHandler.Infof("", "waiting for %d pod(s) to terminate", len(names))
+	err := d.poll(func() (bool, error) {
+		podList, err := d.k8sPodClient.List(metav1.ListOptions{
+			LabelSelector: k8smeta.GetEnvironmentSelector(d.cfg),
+		})
+		if err != nil {
+			return false, err
+		}
+		return !containsAnyPod(podList.Items, names), nil
+	})
+	if err != nil {
+		return fmt.Errorf("error while waiting for pods to terminate: %v", err)
+	}
+	return nil
+}
+
+func containsAnyPod(pods []v1.Pod, names []string) bool {
+	for i := 0; i < len(pods); i++ {
+		for _, name := range names {
+			if pods[i].Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (d *downRunner) run() error {
 	err := d.initKubernetesClientset()
 	if err != nil {
 		return err
 	}
 
-	deletedAllPods, err := d.deletePods()
+	if d.shouldDeleteNamespace() {
+		return d.deleteNamespace()
+	}
+	err = d.getAnchor()
+	if err != nil {
+		return err
+	}
+
+	deletedPods, deletedAllPods, err := d.deletePods()
+	if err != nil {
+		return err
+	}
+	err = d.waitForPodsDeleted(deletedPods)
 	if err != nil {
 		return err
 	}
@@ -107,18 +226,24 @@ func (d *downRunner) run() error {
 	// Only delete services if all pods are to be deleted. This is so that existing pods will not have
 	// their host aliases invalidated.
 	if deletedAllPods {
-		_, err = d.deleteServices()
+		var deletedAllServices bool
+		_, deletedAllServices, err = d.deleteServices()
 		if err != nil {
 			return err
 		}
+		// Deleting the anchor would also delete orphans, so the anchor is kept if orphans are kept.
+		if deletedAllServices && !d.keptOrphans {
+			return d.deleteAnchor()
+		}
 	}
 	return nil
 }
 
 // Run runs a docker-compose down command...
-func Run(cfg *config.Config) error {
+func Run(cfg *config.Config, opts *Options) error {
 	d := &downRunner{
-		cfg: cfg,
+		cfg:  cfg,
+		opts: opts,
 	}
 	return d.run()
 }
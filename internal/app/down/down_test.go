@@ -0,0 +1,134 @@
+package down
+
+import (
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestDownRunner() *downRunner {
+	cfg := &config.Config{}
+	cfg.AddService("a", &dockerComposeConfig.Service{})
+	anchor := &v1.ConfigMap{}
+	anchor.UID = "anchor"
+	return &downRunner{
+		anchor: anchor,
+		cfg:    cfg,
+		opts:   &Options{},
+	}
+}
+
+func TestIsOwned_AnchorSuccess(t *testing.T) {
+	d := newTestDownRunner()
+	objectMeta := &metav1.ObjectMeta{
+		OwnerReferences: []metav1.OwnerReference{
+			k8smeta.NewAnchorOwnerReference(&d.anchor.ObjectMeta),
+		},
+	}
+	if !d.isOwned(objectMeta) {
+		t.Fail()
+	}
+}
+
+func TestIsOwned_AnnotationSuccess(t *testing.T) {
+	d := newTestDownRunner()
+	objectMeta := &metav1.ObjectMeta{
+		Annotations: map[string]string{
+			k8smeta.AnnotationName: "a",
+		},
+	}
+	if !d.isOwned(objectMeta) {
+		t.Fail()
+	}
+}
+
+func TestIsOwned_NotOwned(t *testing.T) {
+	d := newTestDownRunner()
+	if d.isOwned(&metav1.ObjectMeta{}) {
+		t.Fail()
+	}
+	objectMeta := &metav1.ObjectMeta{
+		Annotations: map[string]string{
+			k8smeta.AnnotationName: "a",
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{UID: "other"},
+		},
+	}
+	if d.isOwned(objectMeta) {
+		t.Fail()
+	}
+}
+
+func TestShouldDeleteNamespace(t *testing.T) {
+	d := newTestDownRunner()
+	if err := d.cfg.AddToFilter(d.cfg.FindServiceByName("a")); err != nil {
+		t.Fatal(err)
+	}
+	if d.shouldDeleteNamespace() {
+		t.Fail()
+	}
+	d.cfg.NamespacePerEnvironment = true
+	if !d.shouldDeleteNamespace() {
+		t.Fail()
+	}
+	d.opts.KeepNamespace = true
+	if d.shouldDeleteNamespace() {
+		t.Fail()
+	}
+}
+
+func TestContainsAnyPod(t *testing.T) {
+	pods := []v1.Pod{{}}
+	pods[0].Name = "a"
+	if !containsAnyPod(pods, []string{"b", "a"}) || containsAnyPod(pods, []string{"b"}) {
+		t.Fail()
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	d := newTestDownRunner()
+	if d.timeout() != DefaultTimeout {
+		t.Fail()
+	}
+}
+
+func newTestLister(items ...*metav1.ObjectMeta) lister {
+	return func(_ metav1.ListOptions) ([]*metav1.ObjectMeta, error) {
+		return items, nil
+	}
+}
+
+func TestDeleteCommon_Orphans(t *testing.T) {
+	d := newTestDownRunner()
+	if err := d.cfg.AddToFilter(d.cfg.FindServiceByName("a")); err != nil {
+		t.Fatal(err)
+	}
+	var deletedByDeleter []string
+	deleter := func(name string, _ *metav1.DeleteOptions) error {
+		deletedByDeleter = append(deletedByDeleter, name)
+		return nil
+	}
+	orphan := &metav1.ObjectMeta{
+		Name: "b-myenv",
+		Annotations: map[string]string{
+			k8smeta.AnnotationName: "b",
+		},
+	}
+	notOwned := &metav1.ObjectMeta{
+		Name: "c",
+	}
+	deleted, deletedAll, err := d.deleteCommon("Pod", newTestLister(orphan, notOwned), deleter)
+	if err != nil || len(deleted) != 0 || !deletedAll || !d.keptOrphans || len(deletedByDeleter) != 0 {
+		t.Error(deleted, deletedAll, err, d.keptOrphans, deletedByDeleter)
+	}
+	d.opts.RemoveOrphans = true
+	deleted, _, err = d.deleteCommon("Pod", newTestLister(orphan, notOwned), deleter)
+	if err != nil || len(deleted) != 1 || deleted[0] != "b-myenv" {
+		t.Error(deleted, err)
+	}
+}
@@ -0,0 +1,60 @@
+package down
+
+import (
+	"fmt"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// shouldDeleteNamespace returns true if and only if down should delete the namespace of the environment instead of individual pods and
+// services. This is only the case if each environment has its own namespace, the namespace is not kept and all services are to be deleted.
+func (d *downRunner) shouldDeleteNamespace() bool {
+	if !d.cfg.NamespacePerEnvironment || d.opts.KeepNamespace {
+		return false
+	}
+	for _, service := range d.cfg.Services {
+		if !d.cfg.MatchesFilter(service) {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteNamespace deletes the namespace of the environment and waits until the namespace has been finalized.
+func (d *downRunner) deleteNamespace() error {
+	namespaceClient := d.k8sClientset.CoreV1().Namespaces()
+	namespace, err := namespaceClient.Get(d.cfg.Namespace, metav1.GetOptions{})
+	if k8sError.IsNotFound(err) {
+		d.cfg.EventHandler.Infof("", "namespace %s does not exist", d.cfg.Namespace)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !k8smeta.IsManagedObjectOfEnvironment(d.cfg, &namespace.ObjectMeta) {
+		return fmt.Errorf("namespace %s was not created by kube-compose for this environment, refusing to delete it", d.cfg.Namespace)
+	}
+	err = namespaceClient.Delete(d.cfg.Namespace, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID: &namespace.UID,
+		},
+	})
+	if err != nil && !k8sError.IsNotFound(err) {
+		return err
+	}
+	d.cfg.EventHandler.Infof("", "waiting for namespace %s to be deleted", d.cfg.Namespace)
+	err = d.poll(func() (bool, error) {
+		_, err := namespaceClient.Get(d.cfg.Namespace, metav1.GetOptions{})
+		if k8sError.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("error while waiting for namespace %s to be deleted: %v", d.cfg.Namespace, err)
+	}
+	d.cfg.EventHandler.Infof("", "deleted namespace %s", d.cfg.Namespace)
+	return nil
+}
@@ -0,0 +1,22 @@
+package down
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+type Options struct {
+	// Context cancels waiting for pods or the namespace to be deleted. If it is nil then context.Background() is used.
+	Context context.Context
+	// True to keep the namespace of the environment if each environment has its own namespace. By default the namespace is deleted.
+	KeepNamespace bool
+	// KubernetesClient is the client of the Kubernetes cluster. If it is nil then a client is created from the KubeConfig of the
+	// configuration.
+	KubernetesClient kubernetes.Interface
+	// True to delete pods and services of docker compose services that are no longer in the docker compose file.
+	RemoveOrphans bool
+	// The maximum duration to wait for pods or the namespace to be deleted. Zero means DefaultTimeout.
+	Timeout time.Duration
+}
@@ -0,0 +1,31 @@
+package down
+
+import (
+	"github.com/kube-compose/kube-compose/internal/app/config"
+)
+
+func (d *downRunner) stop() error {
+	err := d.initKubernetesClientset()
+	if err != nil {
+		return err
+	}
+	err = d.getAnchor()
+	if err != nil {
+		return err
+	}
+	deletedPods, _, err := d.deletePods()
+	if err != nil {
+		return err
+	}
+	return d.waitForPodsDeleted(deletedPods)
+}
+
+// Stop deletes the pods of the docker compose services that match the filter, and waits until they have terminated. Unlike Run, Stop
+// keeps the Kubernetes services and the anchor of the environment, so that the pods can be started again with the same cluster IPs.
+func Stop(cfg *config.Config, opts *Options) error {
+	d := &downRunner{
+		cfg:  cfg,
+		opts: opts,
+	}
+	return d.stop()
+}
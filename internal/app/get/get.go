@@ -1,8 +1,11 @@
 package details
 
 import (
+	"context"
+
 	"github.com/kube-compose/kube-compose/internal/app/config"
 	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	clientV1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -10,48 +13,78 @@ import (
 
 type getRunner struct {
 	cfg              *config.Config
-	k8sClientset     *kubernetes.Clientset
+	k8sClientset     kubernetes.Interface
 	k8sServiceClient clientV1.ServiceInterface
 	service          *config.Service
 }
 
+type getServiceResult struct {
+	service *v1.Service
+	err     error
+}
+
 type ServiceDetails struct {
 	Name      string
 	ClusterIP string
 	Hostname  string
 }
 
-func GetServiceDetails(cfg *config.Config, service *config.Service) (*ServiceDetails, error) {
+// GetServiceDetails gets the details of the Kubernetes service of a docker compose service. If k8sClientset is nil then a client is created
+// from the KubeConfig of the configuration. GetServiceDetails returns the error of ctx if ctx is done before the service is retrieved.
+func GetServiceDetails(ctx context.Context, cfg *config.Config, service *config.Service, k8sClientset kubernetes.Interface) (
+	*ServiceDetails, error) {
 	getRunner := &getRunner{
-		cfg:     cfg,
-		service: service,
+		cfg:          cfg,
+		k8sClientset: k8sClientset,
+		service:      service,
 	}
-	return getRunner.run()
+	return getRunner.run(ctx)
 }
 
 func (g *getRunner) initKubernetesClientset() error {
-	k8sClientset, err := kubernetes.NewForConfig(g.cfg.KubeConfig)
-	if err != nil {
-		return err
+	if g.k8sClientset == nil {
+		k8sClientset, err := kubernetes.NewForConfig(g.cfg.KubeConfig)
+		if err != nil {
+			return err
+		}
+		g.k8sClientset = k8sClientset
 	}
-	g.k8sClientset = k8sClientset
 	g.k8sServiceClient = g.k8sClientset.CoreV1().Services(g.cfg.Namespace)
 	return nil
 }
 
-func (g *getRunner) run() (*ServiceDetails, error) {
+// getService gets the Kubernetes service of the docker compose service. The typed clients do not accept a context, so the request is made
+// in a goroutine and abandoned when ctx is done.
+func (g *getRunner) getService(ctx context.Context) (*v1.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	k8sName := k8smeta.GetK8sName(g.service, g.cfg)
+	resultChannel := make(chan getServiceResult, 1)
+	go func() {
+		service, err := g.k8sServiceClient.Get(k8sName, metav1.GetOptions{})
+		resultChannel <- getServiceResult{service: service, err: err}
+	}()
+	select {
+	case r := <-resultChannel:
+		return r.service, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *getRunner) run(ctx context.Context) (*ServiceDetails, error) {
 	err := g.initKubernetesClientset()
 	if err != nil {
 		return nil, err
 	}
-	k8sName := k8smeta.GetK8sName(g.service, g.cfg)
-	result, err := g.k8sServiceClient.Get(k8sName, metav1.GetOptions{})
+	result, err := g.getService(ctx)
 	if err != nil {
 		return nil, err
 	}
 	details := &ServiceDetails{
 		Name:      g.service.Name,
-		Hostname:  result.Name + "." + result.Namespace + ".svc.cluster.local",
+		Hostname:  k8smeta.GetServiceFQDN(g.cfg, result.Name),
 		ClusterIP: result.Spec.ClusterIP,
 	}
 	return details, nil
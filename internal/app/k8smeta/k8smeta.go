@@ -1,16 +1,33 @@
 package k8smeta
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/kube-compose/kube-compose/internal/app/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // AnnotationName is the name of an annotation added by kube compose to resources, so that resources can be mapped back to their docker
 // compose service.
 const AnnotationName = "kube-compose/service"
 
+// AppProtocolsAnnotationName is the name of an annotation of Kubernetes services that maps the names of ports to their application
+// protocols (the app_protocol of the long syntax of ports), encoded as a JSON object.
+const AppProtocolsAnnotationName = "kube-compose/app-protocols"
+
+const (
+	// ManagedByLabel is the label that marks namespaces and anchors that are created by kube-compose.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByLabelValue is the value of ManagedByLabel for namespaces and anchors that are created by kube-compose.
+	ManagedByLabelValue = "kube-compose"
+)
+
 // ErrorResourcesModifiedExternally returns an error indicating that resources managed by kube-compose have been modified externally.
 func ErrorResourcesModifiedExternally() error {
 	return fmt.Errorf("one or more resources appear to have been modified by an external process, aborting")
@@ -21,21 +38,52 @@ func InitCommonLabels(cfg *config.Config, composeService *config.Service, labels
 	if labels == nil {
 		labels = map[string]string{}
 	}
-	labels["app"] = composeService.NameEscaped
+	labels[cfg.AppLabel] = ShortenName(composeService.NameEscaped)
 	labels[cfg.EnvironmentLabel] = cfg.EnvironmentID
 	return labels
 }
 
-// InitObjectMeta sets the name, labels and annotations of a resource for the specified docker compose service.
+// GetEnvironmentSelector returns the label selector that selects all resources of the environment.
+func GetEnvironmentSelector(cfg *config.Config) string {
+	return cfg.EnvironmentLabel + "=" + cfg.EnvironmentID
+}
+
+// InitObjectMeta sets the name, labels and annotations of a resource for the specified docker compose service. The labels and annotations
+// configured by the user are added first, so that they cannot override the labels and annotations managed by kube-compose.
 func InitObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta, composeService *config.Service) {
 	objectMeta.Name = GetK8sName(composeService, cfg)
+	objectMeta.Labels = addStringMap(objectMeta.Labels, composeService.Labels)
 	objectMeta.Labels = InitCommonLabels(cfg, composeService, objectMeta.Labels)
+	objectMeta.Annotations = addStringMap(objectMeta.Annotations, composeService.Annotations)
 	if objectMeta.Annotations == nil {
 		objectMeta.Annotations = map[string]string{}
 	}
 	objectMeta.Annotations[AnnotationName] = composeService.Name
 }
 
+// InitEnvironmentObjectMeta sets the labels and annotations of a resource that is shared by all docker compose services.
+func InitEnvironmentObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) {
+	objectMeta.Labels = addStringMap(objectMeta.Labels, cfg.Labels)
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = map[string]string{}
+	}
+	objectMeta.Labels[cfg.EnvironmentLabel] = cfg.EnvironmentID
+	objectMeta.Annotations = addStringMap(objectMeta.Annotations, cfg.Annotations)
+}
+
+func addStringMap(into, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = make(map[string]string, len(from))
+	}
+	for key, value := range from {
+		into[key] = value
+	}
+	return into
+}
+
 // FindFromObjectMeta finds a docker compose service from resource metadata.
 func FindFromObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) *config.Service {
 	if composeServiceName, ok := objectMeta.Annotations[AnnotationName]; ok {
@@ -47,6 +95,184 @@ func FindFromObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) *conf
 	return nil
 }
 
+// IsOrphan returns true if and only if an object was created by kube-compose for a docker compose service that is no longer in the docker
+// compose file. Objects that reference an owner other than the anchor with the specified UID are never orphans of the environment.
+func IsOrphan(cfg *config.Config, objectMeta *metav1.ObjectMeta, anchorUID types.UID) bool {
+	composeServiceName, ok := objectMeta.Annotations[AnnotationName]
+	if !ok || cfg.FindServiceByName(composeServiceName) != nil {
+		return false
+	}
+	return len(objectMeta.OwnerReferences) == 0 || (anchorUID != "" && IsOwnedBy(objectMeta, anchorUID))
+}
+
+// GetK8sName returns the name of the Kubernetes resources of a docker compose service, as determined by the name template of the
+// configuration. Names that are longer than a DNS label are shortened (see ShortenName).
 func GetK8sName(service *config.Service, cfg *config.Config) string {
-	return service.NameEscaped + "-" + cfg.EnvironmentID
+	var name string
+	switch {
+	case cfg.NameTemplate == nil && cfg.NamespacePerEnvironment:
+		// The namespace isolates the environment, so plain names can be used.
+		name = service.NameEscaped
+	case cfg.NameTemplate == nil:
+		name = service.NameEscaped + "-" + cfg.EnvironmentID
+	default:
+		var sb strings.Builder
+		err := cfg.NameTemplate.Execute(&sb, &config.NameTemplateData{
+			Name:          service.NameEscaped,
+			EnvironmentID: cfg.EnvironmentID,
+			Namespace:     cfg.Namespace,
+		})
+		if err != nil {
+			// This cannot happen, because config.SetNameTemplate executes the template to detect errors.
+			panic(err)
+		}
+		name = sb.String()
+	}
+	return ShortenName(name)
+}
+
+// ValidateK8sNames renders the names of the Kubernetes resources of all docker compose services, and returns an error if a name is not a
+// DNS label or if two docker compose services have the same name. This must be called once the environment ID and namespace have been
+// set, because names can depend on them.
+func ValidateK8sNames(cfg *config.Config) error {
+	services := make([]*config.Service, 0, len(cfg.Services))
+	for _, service := range cfg.Services {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	names := map[string]string{}
+	for _, service := range services {
+		name := GetK8sName(service, cfg)
+		if e := validation.IsDNS1123Label(name); len(e) > 0 {
+			return fmt.Errorf("the name %#v of the resources of docker compose service %s is not a valid DNS label: %s", name, service.Name,
+				e[0])
+		}
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("docker compose services %s and %s have resources with the same name %#v", other, service.Name, name)
+		}
+		names[name] = service.Name
+	}
+	return nil
+}
+
+// ShortenName shortens names that are longer than a DNS label (63 characters), by replacing the end of the name with a hash of the name.
+// This keeps names unique and deterministic.
+func ShortenName(name string) string {
+	if len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+	prefix := strings.TrimRight(name[:validation.DNS1123LabelMaxLength-nameHashLength-1], "-.")
+	return prefix + "-" + nameHash(name)
+}
+
+const nameHashLength = 10
+
+func nameHash(name string) string {
+	hash := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(hash[:])[:nameHashLength]
+}
+
+// GetEnvironmentNamespace returns the namespace of the environment if each environment has its own namespace. The namespace is derived
+// from the environment ID (see getEnvironmentName).
+func GetEnvironmentNamespace(cfg *config.Config) string {
+	return getEnvironmentName(cfg)
+}
+
+// getEnvironmentName returns a DNS label that is unique for each environment ID. Because environment IDs can contain characters that are
+// not allowed in DNS labels, such characters are replaced and a hash of the environment ID is added to keep the name unique.
+func getEnvironmentName(cfg *config.Config) string {
+	name := "kube-compose-" + cfg.EnvironmentID
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+	sanitized := strings.TrimRight(sb.String(), "-")
+	if sanitized != name {
+		sanitized += "-" + nameHash(name)
+	}
+	return ShortenName(sanitized)
+}
+
+// InitNamespaceObjectMeta sets the name and labels of the namespace of the environment.
+func InitNamespaceObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) {
+	objectMeta.Name = cfg.Namespace
+	initManagedObjectMeta(cfg, objectMeta)
+}
+
+// GetAnchorName returns the name of the ConfigMap that anchors the environment. All resources created by kube-compose reference the anchor
+// through their owner references, so that ownership can be determined and the garbage collector can clean up.
+func GetAnchorName(cfg *config.Config) string {
+	return getEnvironmentName(cfg)
+}
+
+// InitAnchorObjectMeta sets the name and labels of the ConfigMap that anchors the environment.
+func InitAnchorObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) {
+	objectMeta.Name = GetAnchorName(cfg)
+	initManagedObjectMeta(cfg, objectMeta)
+}
+
+func initManagedObjectMeta(cfg *config.Config, objectMeta *metav1.ObjectMeta) {
+	InitEnvironmentObjectMeta(cfg, objectMeta)
+	objectMeta.Labels[ManagedByLabel] = ManagedByLabelValue
+}
+
+// IsManagedObjectOfEnvironment returns true if and only if a namespace or anchor was created by kube-compose for the environment.
+func IsManagedObjectOfEnvironment(cfg *config.Config, objectMeta *metav1.ObjectMeta) bool {
+	return objectMeta.Labels[ManagedByLabel] == ManagedByLabelValue && objectMeta.Labels[cfg.EnvironmentLabel] == cfg.EnvironmentID
+}
+
+// NewAnchorOwnerReference returns an owner reference to the ConfigMap that anchors the environment. The owner reference blocks the deletion
+// of the anchor, so that foreground cascading deletion of the anchor waits for all resources of the environment to be deleted.
+func NewAnchorOwnerReference(anchor *metav1.ObjectMeta) metav1.OwnerReference {
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         "v1",
+		Kind:               "ConfigMap",
+		Name:               anchor.Name,
+		UID:                anchor.UID,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
+// IsOwnedBy returns true if and only if an object has an owner reference to the object with the specified UID.
+func IsOwnedBy(objectMeta *metav1.ObjectMeta, uid types.UID) bool {
+	for _, ownerReference := range objectMeta.OwnerReferences {
+		if ownerReference.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSubdomain returns the name of the headless Kubernetes service that backs the subdomain of all pods of an environment. This is used
+// when service discovery is based on DNS.
+func GetSubdomain(cfg *config.Config) string {
+	return getEnvironmentName(cfg)
+}
+
+// GetHostname returns the hostname of the pod of a docker compose service when service discovery is based on DNS. The name of the docker
+// compose service is used if it is a DNS label when converted to lower case, because DNS is case insensitive. Otherwise, the escaped
+// name is used and the second return value is false.
+func GetHostname(service *config.Service) (string, bool) {
+	hostname := strings.ToLower(service.Name)
+	if len(validation.IsDNS1123Label(hostname)) > 0 {
+		return ShortenName(service.NameEscaped), false
+	}
+	return hostname, true
+}
+
+// GetServiceFQDN returns the fully qualified domain name of a Kubernetes service in the namespace of the configuration.
+func GetServiceFQDN(cfg *config.Config, name string) string {
+	clusterDomain := cfg.ClusterDomain
+	if clusterDomain == "" {
+		clusterDomain = config.DefaultClusterDomain
+	}
+	return name + "." + cfg.Namespace + ".svc." + clusterDomain
 }
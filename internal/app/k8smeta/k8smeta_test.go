@@ -1,11 +1,15 @@
 package k8smeta
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/pkg/util"
 	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func newTestConfig() *config.Config {
@@ -55,9 +59,312 @@ func TestInitObjectMeta_Success(t *testing.T) {
 	InitObjectMeta(cfg, &objectMeta, serviceA)
 }
 
+func TestInitObjectMeta_LabelsAndAnnotations(t *testing.T) {
+	cfg := &config.Config{
+		AppLabel:         "app",
+		EnvironmentID:    "myenv",
+		EnvironmentLabel: "env",
+	}
+	serviceA := cfg.AddService("a", &dockerComposeConfig.Service{})
+	serviceA.Labels = map[string]string{
+		"team": "myteam",
+		"app":  "overridden",
+	}
+	serviceA.Annotations = map[string]string{
+		"prometheus.io/scrape": "true",
+		AnnotationName:         "overridden",
+	}
+	objectMeta := metav1.ObjectMeta{}
+	InitObjectMeta(cfg, &objectMeta, serviceA)
+	expectedLabels := map[string]string{
+		"app":  "a",
+		"env":  "myenv",
+		"team": "myteam",
+	}
+	if !reflect.DeepEqual(objectMeta.Labels, expectedLabels) {
+		t.Error(objectMeta.Labels)
+	}
+	expectedAnnotations := map[string]string{
+		AnnotationName:         "a",
+		"prometheus.io/scrape": "true",
+	}
+	if !reflect.DeepEqual(objectMeta.Annotations, expectedAnnotations) {
+		t.Error(objectMeta.Annotations)
+	}
+}
+
+func TestInitEnvironmentObjectMeta(t *testing.T) {
+	cfg := &config.Config{
+		Annotations: map[string]string{
+			"sidecar.istio.io/inject": "false",
+		},
+		EnvironmentID:    "myenv",
+		EnvironmentLabel: "env",
+		Labels: map[string]string{
+			"team": "myteam",
+		},
+	}
+	objectMeta := metav1.ObjectMeta{}
+	InitEnvironmentObjectMeta(cfg, &objectMeta)
+	expectedLabels := map[string]string{
+		"env":  "myenv",
+		"team": "myteam",
+	}
+	if !reflect.DeepEqual(objectMeta.Labels, expectedLabels) {
+		t.Error(objectMeta.Labels)
+	}
+	if !reflect.DeepEqual(objectMeta.Annotations, cfg.Annotations) {
+		t.Error(objectMeta.Annotations)
+	}
+}
+
 func Test_ErrorResourcesModifiedExternally(t *testing.T) {
 	err := ErrorResourcesModifiedExternally()
 	if err == nil {
 		t.Fail()
 	}
 }
+
+func TestGetSubdomain(t *testing.T) {
+	cfg := &config.Config{EnvironmentID: "123", Namespace: "myns"}
+	subdomain := GetSubdomain(cfg)
+	if subdomain != "kube-compose-123" {
+		t.Error(subdomain)
+	}
+	fqdn := GetServiceFQDN(cfg, subdomain)
+	if fqdn != "kube-compose-123.myns.svc.cluster.local" {
+		t.Error(fqdn)
+	}
+}
+
+func TestGetSubdomain_Sanitized(t *testing.T) {
+	cfg := &config.Config{EnvironmentID: "My_Env"}
+	subdomain := GetSubdomain(cfg)
+	if subdomain != GetAnchorName(cfg) || len(validation.IsDNS1123Label(subdomain)) > 0 {
+		t.Error(subdomain)
+	}
+}
+
+func TestGetServiceFQDN_ClusterDomain(t *testing.T) {
+	cfg := &config.Config{Namespace: "myns", ClusterDomain: "example.org"}
+	fqdn := GetServiceFQDN(cfg, "svc")
+	if fqdn != "svc.myns.svc.example.org" {
+		t.Error(fqdn)
+	}
+}
+
+func TestGetK8sName_NameTemplate(t *testing.T) {
+	service := &config.Service{NameEscaped: "test"}
+	cfg := &config.Config{EnvironmentID: "123", Namespace: "myns"}
+	err := cfg.SetNameTemplate("{{.Namespace}}-{{.EnvironmentID}}-{{.Name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceName := GetK8sName(service, cfg)
+	if serviceName != "myns-123-test" {
+		t.Error(serviceName)
+	}
+}
+
+func TestGetK8sName_Shortened(t *testing.T) {
+	service := &config.Service{NameEscaped: strings.Repeat("a", 60)}
+	cfg := &config.Config{EnvironmentID: "123"}
+	serviceName := GetK8sName(service, cfg)
+	if len(serviceName) != 63 || !strings.HasPrefix(serviceName, strings.Repeat("a", 52)+"-") {
+		t.Error(serviceName)
+	}
+	service2 := &config.Service{NameEscaped: strings.Repeat("a", 60) + "b"}
+	if GetK8sName(service2, cfg) == serviceName {
+		t.Fail()
+	}
+}
+
+func TestShortenName_Short(t *testing.T) {
+	name := strings.Repeat("a", 63)
+	if ShortenName(name) != name {
+		t.Fail()
+	}
+}
+
+func TestGetHostname(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hostname string
+		ok       bool
+	}{
+		{name: "db9", hostname: "db9", ok: true},
+		{name: "My-Service", hostname: "my-service", ok: true},
+		{name: "my_service", hostname: util.EscapeName("my_service")},
+		{name: strings.Repeat("a", 64), hostname: ShortenName(util.EscapeName(strings.Repeat("a", 64)))},
+	}
+	for _, testCase := range testCases {
+		service := &config.Service{Name: testCase.name, NameEscaped: util.EscapeName(testCase.name)}
+		hostname, ok := GetHostname(service)
+		if hostname != testCase.hostname || ok != testCase.ok {
+			t.Errorf("%s: %s %v", testCase.name, hostname, ok)
+		}
+	}
+}
+
+func TestInitCommonLabels_LongName(t *testing.T) {
+	cfg := &config.Config{AppLabel: "app", EnvironmentLabel: "env"}
+	service := &config.Service{NameEscaped: strings.Repeat("a", 64)}
+	labels := InitCommonLabels(cfg, service, nil)
+	if len(labels["app"]) != 63 {
+		t.Error(labels)
+	}
+}
+
+func TestInitCommonLabels_CustomLabels(t *testing.T) {
+	cfg := &config.Config{
+		AppLabel:         "kube-compose/app",
+		EnvironmentID:    "myenv",
+		EnvironmentLabel: "kube-compose/env",
+	}
+	serviceA := cfg.AddService("a", &dockerComposeConfig.Service{})
+	labels := InitCommonLabels(cfg, serviceA, nil)
+	expected := map[string]string{
+		"kube-compose/app": "a",
+		"kube-compose/env": "myenv",
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Error(labels)
+	}
+	if selector := GetEnvironmentSelector(cfg); selector != "kube-compose/env=myenv" {
+		t.Error(selector)
+	}
+}
+
+func TestGetK8sName_NamespacePerEnvironment(t *testing.T) {
+	service := &config.Service{NameEscaped: "test"}
+	cfg := &config.Config{EnvironmentID: "123", NamespacePerEnvironment: true}
+	if serviceName := GetK8sName(service, cfg); serviceName != "test" {
+		t.Error(serviceName)
+	}
+}
+
+func TestGetEnvironmentNamespace_Valid(t *testing.T) {
+	cfg := &config.Config{EnvironmentID: "123"}
+	if namespace := GetEnvironmentNamespace(cfg); namespace != "kube-compose-123" {
+		t.Error(namespace)
+	}
+}
+
+func TestGetEnvironmentNamespace_Sanitized(t *testing.T) {
+	cfg1 := &config.Config{EnvironmentID: "My_Env."}
+	namespace1 := GetEnvironmentNamespace(cfg1)
+	if !strings.HasPrefix(namespace1, "kube-compose--y--nv-") || len(namespace1) != len("kube-compose--y--nv-")+10 {
+		t.Error(namespace1)
+	}
+	cfg2 := &config.Config{EnvironmentID: "my_env."}
+	if namespace2 := GetEnvironmentNamespace(cfg2); namespace2 == namespace1 {
+		t.Error(namespace2)
+	}
+}
+
+func TestGetEnvironmentNamespace_Shortened(t *testing.T) {
+	cfg := &config.Config{EnvironmentID: strings.Repeat("a", 63)}
+	if namespace := GetEnvironmentNamespace(cfg); len(namespace) != 63 {
+		t.Error(namespace)
+	}
+}
+
+func TestInitNamespaceObjectMeta(t *testing.T) {
+	cfg := &config.Config{
+		EnvironmentID:    "myenv",
+		EnvironmentLabel: "env",
+		Namespace:        "kube-compose-myenv",
+	}
+	objectMeta := metav1.ObjectMeta{}
+	InitNamespaceObjectMeta(cfg, &objectMeta)
+	if objectMeta.Name != "kube-compose-myenv" || !IsManagedObjectOfEnvironment(cfg, &objectMeta) {
+		t.Error(objectMeta)
+	}
+	cfg.EnvironmentID = "otherenv"
+	if IsManagedObjectOfEnvironment(cfg, &objectMeta) {
+		t.Fail()
+	}
+}
+
+func TestInitAnchorObjectMeta(t *testing.T) {
+	cfg := &config.Config{
+		EnvironmentID:    "myenv",
+		EnvironmentLabel: "env",
+	}
+	objectMeta := metav1.ObjectMeta{}
+	InitAnchorObjectMeta(cfg, &objectMeta)
+	if objectMeta.Name != "kube-compose-myenv" || !IsManagedObjectOfEnvironment(cfg, &objectMeta) {
+		t.Error(objectMeta)
+	}
+}
+
+func TestIsOwnedBy(t *testing.T) {
+	anchor := metav1.ObjectMeta{
+		Name: "kube-compose-myenv",
+		UID:  "uid1",
+	}
+	ownerReference := NewAnchorOwnerReference(&anchor)
+	if ownerReference.Kind != "ConfigMap" || ownerReference.Name != anchor.Name || !*ownerReference.BlockOwnerDeletion {
+		t.Error(ownerReference)
+	}
+	objectMeta := metav1.ObjectMeta{
+		OwnerReferences: []metav1.OwnerReference{ownerReference},
+	}
+	if !IsOwnedBy(&objectMeta, "uid1") || IsOwnedBy(&objectMeta, "uid2") {
+		t.Fail()
+	}
+}
+
+func TestIsOrphan(t *testing.T) {
+	cfg := newTestConfig()
+	objectMeta := metav1.ObjectMeta{
+		Annotations: map[string]string{
+			AnnotationName: "removed",
+		},
+	}
+	if !IsOrphan(cfg, &objectMeta, "") {
+		t.Fail()
+	}
+	objectMeta.OwnerReferences = []metav1.OwnerReference{
+		{UID: "anchor"},
+	}
+	if !IsOrphan(cfg, &objectMeta, "anchor") || IsOrphan(cfg, &objectMeta, "other") || IsOrphan(cfg, &objectMeta, "") {
+		t.Fail()
+	}
+	objectMeta.Annotations[AnnotationName] = "a"
+	if IsOrphan(cfg, &objectMeta, "anchor") {
+		t.Fail()
+	}
+	if IsOrphan(cfg, &metav1.ObjectMeta{}, "") {
+		t.Fail()
+	}
+}
+
+func TestValidateK8sNames_Success(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.EnvironmentID = "123"
+	if err := ValidateK8sNames(cfg); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateK8sNames_InvalidName(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.EnvironmentID = "My_Env"
+	if err := ValidateK8sNames(cfg); err == nil {
+		t.Fail()
+	}
+}
+
+func TestValidateK8sNames_Collision(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.AddService("b", &dockerComposeConfig.Service{})
+	cfg.EnvironmentID = "123"
+	err := cfg.SetNameTemplate("{{.EnvironmentID}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ValidateK8sNames(cfg); err == nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,43 @@
+package up
+
+import (
+	"fmt"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// createAnchor creates the ConfigMap that anchors the environment, or gets it if it already exists. All resources created by up reference
+// the anchor (see initOwnerReferences), which records ownership for down.
+func (u *upRunner) createAnchor() error {
+	configMapClient := u.k8sClientset.CoreV1().ConfigMaps(u.cfg.Namespace)
+	anchor := &v1.ConfigMap{}
+	k8smeta.InitAnchorObjectMeta(u.cfg, &anchor.ObjectMeta)
+	anchorServer, err := configMapClient.Create(anchor)
+	switch {
+	case k8sError.IsAlreadyExists(err):
+		anchorServer, err = configMapClient.Get(anchor.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !k8smeta.IsManagedObjectOfEnvironment(u.cfg, &anchorServer.ObjectMeta) {
+			return fmt.Errorf("configmap %s already exists and was not created by kube-compose for this environment", anchor.Name)
+		}
+	case err != nil:
+		return err
+	default:
+		u.cfg.EventHandler.Infof("", "configmap %s created", anchor.Name)
+	}
+	ownerReference := k8smeta.NewAnchorOwnerReference(&anchorServer.ObjectMeta)
+	u.anchorOwnerReference = &ownerReference
+	return nil
+}
+
+// initOwnerReferences adds an owner reference to the anchor of the environment.
+func (u *upRunner) initOwnerReferences(objectMeta *metav1.ObjectMeta) {
+	if u.anchorOwnerReference != nil {
+		objectMeta.OwnerReferences = append(objectMeta.OwnerReferences, *u.anchorOwnerReference)
+	}
+}
@@ -0,0 +1,146 @@
+package up
+
+import (
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// getPodHostAliases merges the host aliases of the docker compose services (generated by kube-compose) with the extra_hosts of a docker
+// compose service. Entries of extra_hosts that conflict with a generated host alias are ignored, because otherwise the docker compose
+// service would not be able to reach the other docker compose service.
+func getPodHostAliases(a *app, hostAliases []v1.HostAlias) []v1.HostAlias {
+	extraHosts := a.composeService.DockerComposeService.ExtraHosts
+	if len(extraHosts) == 0 {
+		return hostAliases
+	}
+	generated := map[string]bool{}
+	for _, hostAlias := range hostAliases {
+		for _, hostname := range hostAlias.Hostnames {
+			generated[hostname] = true
+		}
+	}
+	result := make([]v1.HostAlias, len(hostAliases), len(hostAliases)+len(extraHosts))
+	copy(result, hostAliases)
+	// Group hostnames by IP so that each IP has one entry in /etc/hosts, preserving the order of extra_hosts.
+	indexByIP := map[string]int{}
+	for _, extraHost := range extraHosts {
+		if generated[extraHost.Hostname] {
+			a.warnf("extra_hosts has an entry for hostname %s that conflicts with the docker compose service of the "+
+				"same name, ignoring this entry", extraHost.Hostname)
+			continue
+		}
+		i, ok := indexByIP[extraHost.IP]
+		if !ok {
+			i = len(result)
+			indexByIP[extraHost.IP] = i
+			result = append(result, v1.HostAlias{
+				IP: extraHost.IP,
+			})
+		}
+		result[i].Hostnames = append(result[i].Hostnames, extraHost.Hostname)
+	}
+	return result
+}
+
+// needsHostAlias returns true if and only if other pods resolve the docker compose service of an app through a host alias. If service
+// discovery is based on DNS then only names that are not hostnames need a host alias, because such names cannot be resolved through the
+// subdomain. Host aliases map names to cluster IPs, so apps without a cluster IP cannot have host aliases.
+func (u *upRunner) needsHostAlias(a *app) bool {
+	if !a.hasClusterIP() {
+		return false
+	}
+	if u.cfg.ServiceDiscovery != config.ServiceDiscoveryDNS {
+		return true
+	}
+	_, ok := k8smeta.GetHostname(a.composeService)
+	return !ok
+}
+
+// initPodHostnameAndSubdomain sets the hostname and subdomain of a pod. If service discovery is based on DNS then the hostname is the name
+// of the docker compose service and the subdomain is the subdomain of the environment. Otherwise, the hostname and domainname of the
+// docker compose service are used. Kubernetes only supports values that are DNS labels, other values are ignored.
+func (u *upRunner) initPodHostnameAndSubdomain(a *app, podSpec *v1.PodSpec) {
+	dcService := a.composeService.DockerComposeService
+	if u.cfg.ServiceDiscovery == config.ServiceDiscoveryDNS {
+		if dcService.Hostname != "" || dcService.DomainName != "" {
+			a.warnf("docker compose service has a hostname or domainname, but these are ignored because service discovery is based on DNS")
+		}
+		hostname, ok := k8smeta.GetHostname(a.composeService)
+		if !ok && !a.hasClusterIP() {
+			a.warnf("the name of the docker compose service is not a valid hostname and the docker compose service has no ports, "+
+				"other docker compose services can resolve it as %s instead", hostname)
+		}
+		podSpec.Hostname = hostname
+		podSpec.Subdomain = k8smeta.GetSubdomain(u.cfg)
+		return
+	}
+	if dcService.Hostname != "" {
+		if e := validation.IsDNS1123Label(dcService.Hostname); len(e) > 0 {
+			a.warnf("docker compose service has an unsupported hostname %#v, ignoring the hostname: %s",
+				dcService.Hostname, e[0])
+		} else {
+			podSpec.Hostname = dcService.Hostname
+		}
+	}
+	if dcService.DomainName != "" {
+		if e := validation.IsDNS1123Label(dcService.DomainName); len(e) > 0 {
+			a.warnf("docker compose service has an unsupported domainname %#v, ignoring the domainname: %s",
+				dcService.DomainName, e[0])
+		} else {
+			podSpec.Subdomain = dcService.DomainName
+		}
+	}
+}
+
+// createPodDNSConfig creates the DNS config of a pod based on the dns and dns_search of a docker compose service.
+// Unlike docker, the nameservers and search domains are merged with the cluster's DNS configuration (as per the ClusterFirst DNS policy),
+// so that the docker compose services can still resolve each other. If service discovery is based on DNS then the subdomain of the
+// environment is the first search domain.
+func (u *upRunner) createPodDNSConfig(a *app) *v1.PodDNSConfig {
+	dcService := a.composeService.DockerComposeService
+	var searches []string
+	if u.cfg.ServiceDiscovery == config.ServiceDiscoveryDNS {
+		searches = append(searches, k8smeta.GetServiceFQDN(u.cfg, k8smeta.GetSubdomain(u.cfg)))
+	}
+	searches = append(searches, dcService.DNSSearch...)
+	if len(dcService.DNS) == 0 && len(searches) == 0 {
+		return nil
+	}
+	return &v1.PodDNSConfig{
+		Nameservers: dcService.DNS,
+		Searches:    searches,
+	}
+}
+
+// createSubdomainService creates the headless Kubernetes service that backs the subdomain of all pods of the environment. Not ready
+// addresses are published so that docker compose services can be resolved as soon as their pods are scheduled, like on a docker network.
+func (u *upRunner) createSubdomainService() error {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: k8smeta.GetSubdomain(u.cfg),
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Selector: map[string]string{
+				u.cfg.EnvironmentLabel: u.cfg.EnvironmentID,
+			},
+			PublishNotReadyAddresses: true,
+		},
+	}
+	k8smeta.InitEnvironmentObjectMeta(u.cfg, &service.ObjectMeta)
+	u.initOwnerReferences(&service.ObjectMeta)
+	_, err := u.k8sServiceClient.Create(service)
+	switch {
+	case k8sError.IsAlreadyExists(err):
+		u.cfg.EventHandler.Infof("", "service %s already exists", service.ObjectMeta.Name)
+	case err != nil:
+		return err
+	default:
+		u.cfg.EventHandler.Infof("", "service %s created", service.ObjectMeta.Name)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package up
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/pkg/util"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetPodHostAliases_NoExtraHosts(t *testing.T) {
+	a := newTestApp("a")
+	hostAliases := []v1.HostAlias{
+		{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"b"},
+		},
+	}
+	result := getPodHostAliases(a, hostAliases)
+	if !reflect.DeepEqual(result, hostAliases) {
+		t.Error(result)
+	}
+}
+
+func TestGetPodHostAliases_Merged(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.ExtraHosts = []dockerComposeConfig.ExtraHost{
+		{
+			Hostname: "stub1",
+			IP:       "10.0.0.2",
+		},
+		{
+			Hostname: "b",
+			IP:       "10.0.0.3",
+		},
+		{
+			Hostname: "stub2",
+			IP:       "10.0.0.2",
+		},
+	}
+	result := getPodHostAliases(a, []v1.HostAlias{
+		{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"b"},
+		},
+	})
+	expected := []v1.HostAlias{
+		{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"b"},
+		},
+		{
+			IP:        "10.0.0.2",
+			Hostnames: []string{"stub1", "stub2"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Error(result)
+	}
+}
+
+func TestInitPodHostnameAndSubdomain_Success(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Hostname = "myhost"
+	a.composeService.DockerComposeService.DomainName = "mydomain"
+	u := &upRunner{
+		cfg: &config.Config{},
+	}
+	podSpec := &v1.PodSpec{}
+	u.initPodHostnameAndSubdomain(a, podSpec)
+	if podSpec.Hostname != "myhost" || podSpec.Subdomain != "mydomain" {
+		t.Error(podSpec)
+	}
+}
+
+func TestInitPodHostnameAndSubdomain_Unsupported(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Hostname = "my_host"
+	a.composeService.DockerComposeService.DomainName = "example.com"
+	u := &upRunner{
+		cfg: &config.Config{},
+	}
+	podSpec := &v1.PodSpec{}
+	u.initPodHostnameAndSubdomain(a, podSpec)
+	if podSpec.Hostname != "" || podSpec.Subdomain != "" {
+		t.Error(podSpec)
+	}
+}
+
+func TestCreatePodDNSConfig_Nil(t *testing.T) {
+	a := newTestApp("a")
+	u := &upRunner{
+		cfg: &config.Config{},
+	}
+	if u.createPodDNSConfig(a) != nil {
+		t.Fail()
+	}
+}
+
+func TestCreatePodDNSConfig_Success(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.DNS = []string{"8.8.8.8"}
+	a.composeService.DockerComposeService.DNSSearch = []string{"example.com"}
+	u := &upRunner{
+		cfg: &config.Config{},
+	}
+	dnsConfig := u.createPodDNSConfig(a)
+	if dnsConfig == nil || !reflect.DeepEqual(dnsConfig.Nameservers, []string{"8.8.8.8"}) ||
+		!reflect.DeepEqual(dnsConfig.Searches, []string{"example.com"}) {
+		t.Error(dnsConfig)
+	}
+}
+
+func TestInitPodHostnameAndSubdomain_ServiceDiscoveryDNS(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Hostname = "myhost"
+	u := newTestUpRunner(nil, nil)
+	u.cfg.ServiceDiscovery = config.ServiceDiscoveryDNS
+	podSpec := &v1.PodSpec{}
+	u.initPodHostnameAndSubdomain(a, podSpec)
+	if podSpec.Hostname != "a" || podSpec.Subdomain != "kube-compose-myenv" {
+		t.Error(podSpec)
+	}
+}
+
+func TestCreatePodDNSConfig_ServiceDiscoveryDNS(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.DNSSearch = []string{"example.com"}
+	u := newTestUpRunner(nil, nil)
+	u.cfg.ServiceDiscovery = config.ServiceDiscoveryDNS
+	dnsConfig := u.createPodDNSConfig(a)
+	if dnsConfig == nil || !reflect.DeepEqual(dnsConfig.Searches, []string{
+		"kube-compose-myenv.myns.svc.cluster.local",
+		"example.com",
+	}) {
+		t.Error(dnsConfig)
+	}
+}
+
+func TestNeedsHostAlias(t *testing.T) {
+	newApp := func(name string, ports []config.Port) *app {
+		return &app{
+			composeService: &config.Service{
+				DockerComposeService: &dockerComposeConfig.Service{},
+				Name:                 name,
+				NameEscaped:          util.EscapeName(name),
+				Ports:                ports,
+			},
+		}
+	}
+	ports := []config.Port{{Port: 80, Protocol: "tcp"}}
+	testCases := []struct {
+		app              *app
+		serviceDiscovery config.ServiceDiscovery
+		expected         bool
+	}{
+		{newApp("web", ports), config.ServiceDiscoveryHostAliases, true},
+		{newApp("web", nil), config.ServiceDiscoveryHostAliases, false},
+		{newApp("web", ports), config.ServiceDiscoveryDNS, false},
+		{newApp("Web", ports), config.ServiceDiscoveryDNS, false},
+		{newApp("my_db", ports), config.ServiceDiscoveryDNS, true},
+		{newApp("my_db", nil), config.ServiceDiscoveryDNS, false},
+	}
+	for _, testCase := range testCases {
+		u := &upRunner{
+			cfg: &config.Config{
+				ServiceDiscovery: testCase.serviceDiscovery,
+			},
+		}
+		if actual := u.needsHostAlias(testCase.app); actual != testCase.expected {
+			t.Errorf("%s %s: %v", testCase.app.name(), testCase.serviceDiscovery, actual)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package up
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kube-compose/kube-compose/internal/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// getTerminationGracePeriodSeconds converts the stop_grace_period of a docker compose service to the termination grace period of a pod.
+// Returns nil if the docker compose service did not set stop_grace_period, so that the Kubernetes default is used.
+func getTerminationGracePeriodSeconds(a *app) *int64 {
+	stopGracePeriod := a.composeService.DockerComposeService.StopGracePeriod
+	if stopGracePeriod == nil {
+		return nil
+	}
+	seconds := new(int64)
+	*seconds = int64(math.Ceil(stopGracePeriod.Seconds()))
+	return seconds
+}
+
+// createLifecycle emulates the stop_signal of a docker compose service. Kubernetes always sends SIGTERM to stop a container, so a preStop
+// hook is used to send the stop signal before that happens. The kubelet sends SIGTERM as soon as the hook returns, so the hook waits
+// until the process has exited, for at most the termination grace period. This requires /bin/sh, kill and sleep to be available in the
+// container.
+// If the pod has an init process (see initPodInit) then PID 1 is the init process, and the signal is sent to all processes of the pod
+// instead.
+func createLifecycle(a *app) *v1.Lifecycle {
+	stopSignal := a.composeService.DockerComposeService.StopSignal
+	if stopSignal == "" {
+		return nil
+	}
+	var killArgs string
+	if _, err := strconv.ParseUint(stopSignal, 10, 8); err == nil {
+		if stopSignal == "15" {
+			return nil
+		}
+		killArgs = "-" + stopSignal
+	} else {
+		name := strings.TrimPrefix(strings.ToUpper(stopSignal), "SIG")
+		if !isSignalName(name) {
+			a.warnf("docker compose service has an invalid stop_signal %#v, ignoring the stop_signal", stopSignal)
+			return nil
+		}
+		if name == "TERM" {
+			return nil
+		}
+		killArgs = "-s " + name
+	}
+	pid := "1"
+	if a.composeService.DockerComposeService.Init {
+		pid = "-1"
+	}
+	gracePeriodSeconds := int64(v1.DefaultTerminationGracePeriodSeconds)
+	if seconds := getTerminationGracePeriodSeconds(a); seconds != nil {
+		gracePeriodSeconds = *seconds
+	}
+	return &v1.Lifecycle{
+		PreStop: &v1.Handler{
+			Exec: &v1.ExecAction{
+				Command: []string{
+					"/bin/sh",
+					"-c",
+					fmt.Sprintf("kill %s %s; i=0; while [ $i -lt %d ] && kill -0 %s 2>/dev/null; do sleep 1; i=$((i+1)); done",
+						killArgs, pid, gracePeriodSeconds, pid),
+				},
+			},
+		},
+	}
+}
+
+func isSignalName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if !(b >= 'A' && b <= 'Z') && !(b >= '0' && b <= '9') && b != '+' && b != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// initPodInit emulates the init of a docker compose service by sharing the process namespace of the pod's containers. The pod's
+// infrastructure container then runs as PID 1, which reaps zombie processes like docker's init does.
+func initPodInit(a *app, podSpec *v1.PodSpec) {
+	if a.composeService.DockerComposeService.Init {
+		podSpec.ShareProcessNamespace = util.NewBool(true)
+	}
+}
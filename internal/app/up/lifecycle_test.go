@@ -0,0 +1,111 @@
+package up
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetTerminationGracePeriodSeconds_Unset(t *testing.T) {
+	a := newTestApp("a")
+	if result := getTerminationGracePeriodSeconds(a); result != nil {
+		t.Fail()
+	}
+}
+
+func TestGetTerminationGracePeriodSeconds_RoundsUp(t *testing.T) {
+	a := newTestApp("a")
+	d := 1500 * time.Millisecond
+	a.composeService.DockerComposeService.StopGracePeriod = &d
+	result := getTerminationGracePeriodSeconds(a)
+	if result == nil || *result != 2 {
+		t.Error(result)
+	}
+}
+
+func TestCreateLifecycle_Unset(t *testing.T) {
+	a := newTestApp("a")
+	if result := createLifecycle(a); result != nil {
+		t.Error(result)
+	}
+}
+
+func TestCreateLifecycle_Term(t *testing.T) {
+	a := newTestApp("a")
+	for _, stopSignal := range []string{"SIGTERM", "term", "15"} {
+		a.composeService.DockerComposeService.StopSignal = stopSignal
+		if result := createLifecycle(a); result != nil {
+			t.Error(stopSignal, result)
+		}
+	}
+}
+
+func TestCreateLifecycle_Invalid(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.StopSignal = "INT; rm -rf /"
+	if result := createLifecycle(a); result != nil {
+		t.Error(result)
+	}
+}
+
+func TestCreateLifecycle_Success(t *testing.T) {
+	stopGracePeriod := 10 * time.Second
+	testCases := []struct {
+		stopSignal      string
+		init            bool
+		stopGracePeriod *time.Duration
+		command         string
+	}{
+		{
+			stopSignal: "SIGINT",
+			command:    "kill -s INT 1; i=0; while [ $i -lt 30 ] && kill -0 1 2>/dev/null; do sleep 1; i=$((i+1)); done",
+		},
+		{
+			stopSignal:      "quit",
+			stopGracePeriod: &stopGracePeriod,
+			command:         "kill -s QUIT 1; i=0; while [ $i -lt 10 ] && kill -0 1 2>/dev/null; do sleep 1; i=$((i+1)); done",
+		},
+		{
+			stopSignal: "9",
+			command:    "kill -9 1; i=0; while [ $i -lt 30 ] && kill -0 1 2>/dev/null; do sleep 1; i=$((i+1)); done",
+		},
+		{
+			stopSignal: "SIGUSR1",
+			init:       true,
+			command:    "kill -s USR1 -1; i=0; while [ $i -lt 30 ] && kill -0 -1 2>/dev/null; do sleep 1; i=$((i+1)); done",
+		},
+	}
+	for _, testCase := range testCases {
+		a := newTestApp("a")
+		a.composeService.DockerComposeService.StopSignal = testCase.stopSignal
+		a.composeService.DockerComposeService.Init = testCase.init
+		a.composeService.DockerComposeService.StopGracePeriod = testCase.stopGracePeriod
+		result := createLifecycle(a)
+		expected := &v1.Lifecycle{
+			PreStop: &v1.Handler{
+				Exec: &v1.ExecAction{
+					Command: []string{"/bin/sh", "-c", testCase.command},
+				},
+			},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Error(testCase.stopSignal, result)
+		}
+	}
+}
+
+func TestInitPodInit(t *testing.T) {
+	a := newTestApp("a")
+	podSpec := &v1.PodSpec{}
+	initPodInit(a, podSpec)
+	if podSpec.ShareProcessNamespace != nil {
+		t.Fail()
+	}
+	a.composeService.DockerComposeService.Init = true
+	initPodInit(a, podSpec)
+	if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+		t.Fail()
+	}
+}
@@ -0,0 +1,156 @@
+package up
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type ulimitFlag struct {
+	flag string
+	// unit is the number of bytes per unit of the ulimit shell builtin, or 1 if the ulimit is not a size.
+	unit int64
+}
+
+// ulimitFlags maps the names of ulimits to the flags of the ulimit shell builtin. Only flags that dash, bash and busybox agree on are
+// included, because the shell of an image can be any of these. Docker compose specifies sizes in bytes, but the ulimit shell builtin
+// counts kibibytes, or 512-byte blocks for file sizes.
+var ulimitFlags = map[string]ulimitFlag{
+	"as":      {flag: "-v", unit: 1024},
+	"core":    {flag: "-c", unit: 512},
+	"cpu":     {flag: "-t", unit: 1},
+	"data":    {flag: "-d", unit: 1024},
+	"fsize":   {flag: "-f", unit: 512},
+	"memlock": {flag: "-l", unit: 1024},
+	"nofile":  {flag: "-n", unit: 1},
+	"rss":     {flag: "-m", unit: 1024},
+	"stack":   {flag: "-s", unit: 1024},
+}
+
+// createSysctls converts the sysctls of a docker compose service to the sysctls of a pod. Note that Kubernetes only allows unsafe
+// sysctls if they have been whitelisted on the kubelet:
+// https://kubernetes.io/docs/tasks/administer-cluster/sysctl-cluster/
+func createSysctls(a *app) []v1.Sysctl {
+	sysctls := a.composeService.DockerComposeService.Sysctls
+	if len(sysctls) == 0 {
+		return nil
+	}
+	result := make([]v1.Sysctl, 0, len(sysctls))
+	for name, value := range sysctls {
+		result = append(result, v1.Sysctl{
+			Name:  name,
+			Value: value,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// initPodShm emulates the shm_size of a docker compose service by mounting a memory-backed emptyDir volume at /dev/shm.
+func initPodShm(a *app, pod *v1.Pod) {
+	shmSize := a.composeService.DockerComposeService.ShmSize
+	if shmSize == nil {
+		return
+	}
+	const volumeName = "dshm"
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name: volumeName,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{
+				Medium:    v1.StorageMediumMemory,
+				SizeLimit: resource.NewQuantity(*shmSize, resource.BinarySI),
+			},
+		},
+	})
+	container := &pod.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      volumeName,
+		MountPath: "/dev/shm",
+	})
+}
+
+// getUlimitCommands returns the ulimit shell commands that emulate the ulimits of a docker compose service, sorted by the name of the
+// ulimit. Ulimits that cannot be applied reliably are ignored with a warning.
+func getUlimitCommands(a *app) []string {
+	ulimits := a.composeService.DockerComposeService.Ulimits
+	names := make([]string, 0, len(ulimits))
+	for name := range ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var commands []string
+	for _, name := range names {
+		flag, ok := ulimitFlags[name]
+		if !ok {
+			a.warnf("ulimit %s is not supported, ignoring it", name)
+			continue
+		}
+		ulimit := ulimits[name]
+		soft := formatUlimit(ulimit.Soft, flag.unit)
+		hard := formatUlimit(ulimit.Hard, flag.unit)
+		if soft == hard {
+			// Without -H and -S both limits are set at once, which works regardless of the current limits.
+			commands = append(commands, fmt.Sprintf("ulimit %s %s", flag.flag, hard))
+			continue
+		}
+		// The current limits are unknown: the soft limit cannot exceed the current hard limit, and the hard limit cannot be lowered below
+		// the current soft limit. Setting the soft limit before and after the hard limit works in either case. The first attempt fails if
+		// the limits are raised, so its error is discarded.
+		commands = append(commands,
+			fmt.Sprintf("ulimit -S %s %s 2>/dev/null", flag.flag, soft),
+			fmt.Sprintf("ulimit -H %s %s", flag.flag, hard),
+			fmt.Sprintf("ulimit -S %s %s", flag.flag, soft),
+		)
+	}
+	return commands
+}
+
+// formatUlimit formats a limit of docker compose as an argument of the ulimit shell builtin, where unit is the number of bytes per unit
+// of the ulimit shell builtin. Sizes are rounded down so that the limit is not exceeded.
+func formatUlimit(limit, unit int64) string {
+	if limit < 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(limit/unit, 10)
+}
+
+// wrapCommandWithUlimits emulates the ulimits of a docker compose service by running the command of the container through a shell that
+// sets the ulimits. Raising hard limits requires the CAP_SYS_RESOURCE capability, so if the runtime does not allow a ulimit then the
+// shell prints an error and the command runs anyway. This requires /bin/sh to be available in the container.
+func (a *app) wrapCommandWithUlimits(c *v1.Container) error {
+	commands := getUlimitCommands(a)
+	if len(commands) == 0 {
+		return nil
+	}
+	// Determine the command line that the container would run, since the image's entrypoint and command are ignored once the
+	// container's command is set.
+	var commandLine []string
+	if len(c.Command) > 0 {
+		commandLine = append(commandLine, c.Command...)
+		commandLine = append(commandLine, c.Args...)
+	} else {
+		commandLine = append(commandLine, a.imageInfo.entrypoint...)
+		if len(c.Args) > 0 {
+			commandLine = append(commandLine, c.Args...)
+		} else {
+			commandLine = append(commandLine, a.imageInfo.cmd...)
+		}
+	}
+	if len(commandLine) == 0 {
+		return fmt.Errorf("cannot create container for app %s because it would have no command", a.name())
+	}
+	c.Command = []string{
+		"/bin/sh",
+		"-c",
+		strings.Join(commands, "; ") + `; exec "$@"`,
+		"sh",
+	}
+	c.Args = commandLine
+	return nil
+}
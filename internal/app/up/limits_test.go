@@ -0,0 +1,206 @@
+package up
+
+import (
+	"reflect"
+	"testing"
+
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCreateSysctls(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Sysctls = map[string]string{
+		"net.ipv4.tcp_syncookies": "0",
+		"net.core.somaxconn":      "1024",
+	}
+	result := createSysctls(a)
+	expected := []v1.Sysctl{
+		{
+			Name:  "net.core.somaxconn",
+			Value: "1024",
+		},
+		{
+			Name:  "net.ipv4.tcp_syncookies",
+			Value: "0",
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Error(result)
+	}
+}
+
+func TestInitPodShm(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.ShmSize = new(int64)
+	*a.composeService.DockerComposeService.ShmSize = 64 * 1024 * 1024
+	pod := newTestPod(a)
+	initPodShm(a, pod)
+	expectedVolumes := []v1.Volume{
+		{
+			Name: "dshm",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{
+					Medium:    v1.StorageMediumMemory,
+					SizeLimit: resource.NewQuantity(64*1024*1024, resource.BinarySI),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(pod.Spec.Volumes, expectedVolumes) {
+		t.Error(pod.Spec.Volumes)
+	}
+	expectedVolumeMounts := []v1.VolumeMount{
+		{
+			Name:      "dshm",
+			MountPath: "/dev/shm",
+		},
+	}
+	if !reflect.DeepEqual(pod.Spec.Containers[0].VolumeMounts, expectedVolumeMounts) {
+		t.Error(pod.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestWrapCommandWithUlimits_NoUlimits(t *testing.T) {
+	a := newTestApp("a")
+	c := &v1.Container{}
+	err := a.wrapCommandWithUlimits(c)
+	if err != nil || c.Command != nil || c.Args != nil {
+		t.Error(c, err)
+	}
+}
+
+func TestWrapCommandWithUlimits_ImageCommandLine(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"nofile": {
+			Soft: 20000,
+			Hard: 40000,
+		},
+		"memlock": {
+			Soft: -1,
+			Hard: -1,
+		},
+		"nproc": {
+			Soft: 65535,
+			Hard: 65535,
+		},
+	}
+	a.imageInfo.entrypoint = []string{"docker-entrypoint.sh"}
+	a.imageInfo.cmd = []string{"postgres"}
+	c := &v1.Container{}
+	err := a.wrapCommandWithUlimits(c)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedCommand := []string{
+		"/bin/sh",
+		"-c",
+		`ulimit -l unlimited; ulimit -S -n 20000 2>/dev/null; ulimit -H -n 40000; ulimit -S -n 20000; exec "$@"`,
+		"sh",
+	}
+	if !reflect.DeepEqual(c.Command, expectedCommand) {
+		t.Error(c.Command)
+	}
+	if !reflect.DeepEqual(c.Args, []string{"docker-entrypoint.sh", "postgres"}) {
+		t.Error(c.Args)
+	}
+}
+
+func TestGetUlimitCommands_SoftEqualsHard(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"nofile": {
+			Soft: 1024,
+			Hard: 1024,
+		},
+	}
+	// A single command sets both limits, so this works when lowering and when raising the limits.
+	commands := getUlimitCommands(a)
+	expected := []string{
+		"ulimit -n 1024",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Error(commands)
+	}
+}
+
+func TestGetUlimitCommands_SoftLessThanHard(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"nofile": {
+			Soft: 512,
+			Hard: 1024,
+		},
+	}
+	// When lowering the limits the first command lowers the soft limit so that the hard limit can be lowered. When raising the limits the
+	// first command fails, and the last command raises the soft limit once the hard limit has been raised.
+	commands := getUlimitCommands(a)
+	expected := []string{
+		"ulimit -S -n 512 2>/dev/null",
+		"ulimit -H -n 1024",
+		"ulimit -S -n 512",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Error(commands)
+	}
+}
+
+func TestGetUlimitCommands_Units(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"core": {
+			Soft: 1024 * 1024,
+			Hard: 1024 * 1024,
+		},
+		"cpu": {
+			Soft: 10,
+			Hard: 10,
+		},
+		"stack": {
+			Soft: 8 * 1024 * 1024,
+			Hard: -1,
+		},
+	}
+	commands := getUlimitCommands(a)
+	expected := []string{
+		"ulimit -c 2048",
+		"ulimit -t 10",
+		"ulimit -S -s 8192 2>/dev/null",
+		"ulimit -H -s unlimited",
+		"ulimit -S -s 8192",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Error(commands)
+	}
+}
+
+func TestWrapCommandWithUlimits_ContainerCommandLine(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"core": {},
+	}
+	a.imageInfo.cmd = []string{"ignored"}
+	c := &v1.Container{
+		Command: []string{"echo"},
+	}
+	err := a.wrapCommandWithUlimits(c)
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(c.Args, []string{"echo"}) {
+		t.Error(c.Args)
+	}
+}
+
+func TestWrapCommandWithUlimits_NoCommandError(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Ulimits = map[string]dockerComposeConfig.Ulimit{
+		"core": {},
+	}
+	err := a.wrapCommandWithUlimits(&v1.Container{})
+	if err == nil {
+		t.Fail()
+	}
+}
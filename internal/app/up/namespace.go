@@ -0,0 +1,46 @@
+package up
+
+import (
+	"fmt"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// createNamespace creates the namespace of the environment if each environment has its own namespace. An existing namespace is only
+// reused if it was created by kube-compose for the same environment, so that up never deploys into a namespace it does not own.
+func (u *upRunner) createNamespace() error {
+	if !u.cfg.NamespacePerEnvironment {
+		return nil
+	}
+	namespace := &v1.Namespace{}
+	k8smeta.InitNamespaceObjectMeta(u.cfg, &namespace.ObjectMeta)
+	_, err := u.k8sClientset.CoreV1().Namespaces().Create(namespace)
+	switch {
+	case k8sError.IsAlreadyExists(err):
+		namespace, err = u.k8sClientset.CoreV1().Namespaces().Get(u.cfg.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		return checkExistingNamespace(u.cfg, namespace)
+	case err != nil:
+		return err
+	default:
+		u.cfg.EventHandler.Infof("", "namespace %s created", u.cfg.Namespace)
+	}
+	return nil
+}
+
+func checkExistingNamespace(cfg *config.Config, namespace *v1.Namespace) error {
+	if !k8smeta.IsManagedObjectOfEnvironment(cfg, &namespace.ObjectMeta) {
+		return fmt.Errorf("namespace %s already exists and was not created by kube-compose for this environment", namespace.Name)
+	}
+	if namespace.Status.Phase == v1.NamespaceTerminating {
+		return fmt.Errorf("namespace %s is being deleted, try again after it has been deleted", namespace.Name)
+	}
+	cfg.EventHandler.Infof("", "namespace %s already exists", namespace.Name)
+	return nil
+}
@@ -0,0 +1,49 @@
+package up
+
+import (
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+)
+
+func newTestNamespaceConfig() *config.Config {
+	return &config.Config{
+		EnvironmentID:           "myenv",
+		EnvironmentLabel:        config.DefaultEnvironmentLabel,
+		Namespace:               "kube-compose-myenv",
+		NamespacePerEnvironment: true,
+	}
+}
+
+func TestCheckExistingNamespace_Success(t *testing.T) {
+	cfg := newTestNamespaceConfig()
+	namespace := &v1.Namespace{}
+	k8smeta.InitNamespaceObjectMeta(cfg, &namespace.ObjectMeta)
+	err := checkExistingNamespace(cfg, namespace)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckExistingNamespace_NotOwnedError(t *testing.T) {
+	cfg := newTestNamespaceConfig()
+	namespace := &v1.Namespace{}
+	namespace.Name = cfg.Namespace
+	err := checkExistingNamespace(cfg, namespace)
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestCheckExistingNamespace_TerminatingError(t *testing.T) {
+	cfg := newTestNamespaceConfig()
+	namespace := &v1.Namespace{}
+	k8smeta.InitNamespaceObjectMeta(cfg, &namespace.ObjectMeta)
+	namespace.Status.Phase = v1.NamespaceTerminating
+	err := checkExistingNamespace(cfg, namespace)
+	if err == nil {
+		t.Fail()
+	}
+}
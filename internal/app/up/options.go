@@ -2,11 +2,47 @@ package up
 
 import (
 	"context"
+	"io"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	dockerContainers "github.com/docker/docker/api/types/container"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/kube-compose/kube-compose/internal/pkg/docker"
+	"k8s.io/client-go/kubernetes"
 )
 
+// DockerClient is the part of the docker API that up uses to pull, build, inspect, tag and push images. It is implemented by the client of
+// github.com/docker/docker/client.
+type DockerClient interface {
+	docker.ImagePuller
+	docker.ImagePusher
+	ContainerCreate(ctx context.Context, config *dockerContainers.Config, hostConfig *dockerContainers.HostConfig,
+		networkingConfig *dockerNetwork.NetworkingConfig, containerName string) (dockerContainers.ContainerCreateCreatedBody, error)
+	ContainerRemove(ctx context.Context, containerID string, options dockerTypes.ContainerRemoveOptions) error
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, dockerTypes.ContainerPathStat, error)
+	ImageBuild(ctx context.Context, buildContext io.Reader, options dockerTypes.ImageBuildOptions) (dockerTypes.ImageBuildResponse, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (dockerTypes.ImageInspect, []byte, error)
+	ImageList(ctx context.Context, options dockerTypes.ImageListOptions) ([]dockerTypes.ImageSummary, error)
+	ImageTag(ctx context.Context, imageID, ref string) error
+}
+
 type Options struct {
+	// Context cancels the requests to the docker daemon, waiting for cluster IPs and pods, and streaming the logs of pods. If it is nil
+	// then context.Background() is used.
 	Context context.Context
 	Detach  bool
+	// DockerClient is the client of the docker daemon that has the images of the docker compose services. If it is nil then a client is
+	// created from the environment variables DOCKER_HOST, DOCKER_API_VERSION, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY.
+	DockerClient DockerClient
+	// True to recreate existing pods even if their spec did not change.
+	ForceRecreate bool
+	// KubernetesClient is the client of the Kubernetes cluster. If it is nil then a client is created from the KubeConfig of the
+	// configuration.
+	KubernetesClient kubernetes.Interface
+	// True to never recreate existing pods, even if their spec changed.
+	NoRecreate bool
+	// True to delete pods and services of docker compose services that are no longer in the docker compose file.
+	RemoveOrphans bool
 	// True to set runAsUser/runAsGroup for each pod based on the user of the pod's image and the "user" key of the pod's docker-compose
 	// service.
 	RunAsUser bool
@@ -0,0 +1,64 @@
+package up
+
+import (
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// handleOrphans warns about pods and services of docker compose services that are no longer in the docker compose file, or deletes them if
+// orphans should be removed.
+func (u *upRunner) handleOrphans() error {
+	listOptions := metav1.ListOptions{
+		LabelSelector: k8smeta.GetEnvironmentSelector(u.cfg),
+	}
+	podList, err := u.k8sPodClient.List(listOptions)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(podList.Items); i++ {
+		err = u.handleOrphan("pod", &podList.Items[i].ObjectMeta, u.k8sPodClient.Delete)
+		if err != nil {
+			return err
+		}
+	}
+	serviceList, err := u.k8sServiceClient.List(listOptions)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(serviceList.Items); i++ {
+		err = u.handleOrphan("service", &serviceList.Items[i].ObjectMeta, u.k8sServiceClient.Delete)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *upRunner) handleOrphan(kind string, objectMeta *metav1.ObjectMeta, deleter func(string, *metav1.DeleteOptions) error) error {
+	if !u.isOrphan(objectMeta) {
+		return nil
+	}
+	composeServiceName := objectMeta.Annotations[k8smeta.AnnotationName]
+	if !u.opts.RemoveOrphans {
+		u.cfg.EventHandler.Warnf("", "found orphan %s %s of service %s that is not in the docker compose file, use --remove-orphans to "+
+			"delete it", kind, objectMeta.Name, composeServiceName)
+		return nil
+	}
+	propagationPolicy := metav1.DeletePropagationForeground
+	err := deleter(objectMeta.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+	if err != nil && !k8sError.IsNotFound(err) {
+		return err
+	}
+	u.cfg.EventHandler.Infof("", "deleted orphan %s %s of service %s", kind, objectMeta.Name, composeServiceName)
+	return nil
+}
+
+func (u *upRunner) isOrphan(objectMeta *metav1.ObjectMeta) bool {
+	if u.anchorOwnerReference == nil {
+		return k8smeta.IsOrphan(u.cfg, objectMeta, "")
+	}
+	return k8smeta.IsOrphan(u.cfg, objectMeta, u.anchorOwnerReference.UID)
+}
@@ -0,0 +1,49 @@
+package up
+
+import (
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestOrphanObjectMeta(composeServiceName string) *metav1.ObjectMeta {
+	return &metav1.ObjectMeta{
+		Name: composeServiceName + "-myenv",
+		Annotations: map[string]string{
+			k8smeta.AnnotationName: composeServiceName,
+		},
+	}
+}
+
+func TestHandleOrphan_RemoveOrphans(t *testing.T) {
+	u := newTestUpRunner(&Options{RemoveOrphans: true}, nil)
+	var deleted []string
+	deleter := func(name string, _ *metav1.DeleteOptions) error {
+		deleted = append(deleted, name)
+		return nil
+	}
+	err := u.handleOrphan("pod", newTestOrphanObjectMeta("a"), deleter)
+	if err != nil {
+		t.Error(err)
+	}
+	err = u.handleOrphan("pod", newTestOrphanObjectMeta("e"), deleter)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(deleted) != 1 || deleted[0] != "e-myenv" {
+		t.Error(deleted)
+	}
+}
+
+func TestHandleOrphan_Warn(t *testing.T) {
+	u := newTestUpRunner(&Options{RemoveOrphans: false}, nil)
+	deleter := func(name string, _ *metav1.DeleteOptions) error {
+		t.Fail()
+		return nil
+	}
+	err := u.handleOrphan("service", newTestOrphanObjectMeta("e"), deleter)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,49 @@
+package up
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// initPodSpecOverrides sets the fields of a pod spec that are configured in the x-kube-compose of a docker compose service.
+func initPodSpecOverrides(a *app, podSpec *v1.PodSpec) {
+	overrides := &a.composeService.PodSpecOverrides
+	podSpec.Affinity = overrides.Affinity
+	podSpec.ImagePullSecrets = overrides.ImagePullSecrets
+	podSpec.NodeSelector = overrides.NodeSelector
+	podSpec.PriorityClassName = overrides.PriorityClassName
+	podSpec.RuntimeClassName = overrides.RuntimeClassName
+	podSpec.ServiceAccountName = overrides.ServiceAccountName
+	podSpec.Tolerations = overrides.Tolerations
+	if overrides.ServiceAccountName != "" {
+		// The service account token is only useful if the pod runs with a specific service account, so only then use the default of
+		// the service account.
+		podSpec.AutomountServiceAccountToken = nil
+	}
+}
+
+// applyPodPatch applies the pod_patch of the x-kube-compose of a docker compose service to a pod.
+func applyPodPatch(a *app, pod *v1.Pod) error {
+	podPatch := a.composeService.PodSpecOverrides.PodPatch
+	if podPatch == nil {
+		return nil
+	}
+	podJSON, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	podJSON, err = strategicpatch.StrategicMergePatch(podJSON, podPatch, v1.Pod{})
+	if err != nil {
+		return errors.Wrapf(err, "error while applying the pod_patch of app %s", a.name())
+	}
+	patched := &v1.Pod{}
+	err = json.Unmarshal(podJSON, patched)
+	if err != nil {
+		return err
+	}
+	*pod = *patched
+	return nil
+}
@@ -0,0 +1,60 @@
+package up
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestInitPodSpecOverrides(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.PodSpecOverrides = config.PodSpecOverrides{
+		NodeSelector: map[string]string{
+			"disktype": "ssd",
+		},
+		ServiceAccountName: "ci",
+	}
+	podSpec := &v1.PodSpec{
+		AutomountServiceAccountToken: new(bool),
+	}
+	initPodSpecOverrides(a, podSpec)
+	expected := &v1.PodSpec{
+		NodeSelector: map[string]string{
+			"disktype": "ssd",
+		},
+		ServiceAccountName: "ci",
+	}
+	if !reflect.DeepEqual(podSpec, expected) {
+		t.Error(podSpec)
+	}
+}
+
+func TestApplyPodPatch_NoPatch(t *testing.T) {
+	a := newTestApp("a")
+	pod := newTestPod(a)
+	err := applyPodPatch(a, pod)
+	if err != nil || !reflect.DeepEqual(pod, newTestPod(a)) {
+		t.Error(pod, err)
+	}
+}
+
+func TestApplyPodPatch_Success(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.PodSpecOverrides.PodPatch = []byte(`{"spec":{"containers":[{"name":"a","resources":{"limits":{"cpu":"1"}}}]}}`)
+	pod := newTestPod(a)
+	pod.Spec.Containers[0].Image = "ubuntu:latest"
+	err := applyPodPatch(a, pod)
+	if err != nil {
+		t.Error(err)
+	}
+	c := pod.Spec.Containers[0]
+	if c.Image != "ubuntu:latest" {
+		t.Error(c.Image)
+	}
+	if c.Resources.Limits.Cpu().Cmp(resource.MustParse("1")) != 0 {
+		t.Error(c.Resources.Limits)
+	}
+}
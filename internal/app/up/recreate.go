@@ -0,0 +1,222 @@
+package up
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const annotationSpecHash = "kube-compose/spec-hash"
+
+const (
+	podDeletePollInterval = time.Second
+	// podDeleteTimeoutMargin is added to the termination grace period of a pod to determine how long to wait for the pod to be deleted,
+	// because the kubelet and API server need some time after the containers have been killed.
+	podDeleteTimeoutMargin = time.Minute
+)
+
+// setPodSpecHash sets an annotation with a hash of the metadata and spec of a pod, so that up can detect whether an existing pod is stale.
+// The ID of the source image is included, because the pod image does not change if a tag is moved to a different image.
+func setPodSpecHash(a *app, pod *v1.Pod) error {
+	data, err := json.Marshal(struct {
+		Annotations   map[string]string `json:"annotations"`
+		Labels        map[string]string `json:"labels"`
+		Spec          *v1.PodSpec       `json:"spec"`
+		SourceImageID string            `json:"sourceImageID"`
+	}{
+		Annotations:   pod.Annotations,
+		Labels:        pod.Labels,
+		Spec:          &pod.Spec,
+		SourceImageID: a.imageInfo.sourceImageID,
+	})
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotationSpecHash] = hex.EncodeToString(hash[:])
+	return nil
+}
+
+// recreatePodsIfNeeded deletes the existing pods of apps that are to be started whose spec changed, together with the existing pods of
+// their dependents. The deleted pods are then recreated in dependency order when the apps are started.
+func (u *upRunner) recreatePodsIfNeeded() error {
+	if u.opts.NoRecreate {
+		return nil
+	}
+	existingPods, err := u.getExistingPods()
+	if err != nil {
+		return err
+	}
+	reasons := map[*app]string{}
+	for a, pod := range existingPods {
+		if u.opts.ForceRecreate {
+			reasons[a] = "--force-recreate is set"
+			continue
+		}
+		desiredPod, err := u.buildPod(a)
+		if err != nil {
+			return err
+		}
+		if pod.Annotations[annotationSpecHash] != desiredPod.Annotations[annotationSpecHash] {
+			reasons[a] = "its spec changed"
+		}
+	}
+	u.addDependentsToRecreate(reasons)
+	apps := u.sortAppsByDependencies(reasons)
+	var deletedPods []*v1.Pod
+	// Dependents are deleted before their dependencies.
+	for i := len(apps) - 1; i >= 0; i-- {
+		a := apps[i]
+		pod := existingPods[a]
+		if pod == nil {
+			continue
+		}
+		err = u.k8sPodClient.Delete(pod.Name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{
+				UID: &pod.UID,
+			},
+		})
+		if err != nil && !k8sError.IsNotFound(err) {
+			return err
+		}
+		a.infof("deleted pod %s to recreate it because %s", pod.Name, reasons[a])
+		deletedPods = append(deletedPods, pod)
+	}
+	return u.waitForPodsDeleted(deletedPods)
+}
+
+func (u *upRunner) getExistingPods() (map[*app]*v1.Pod, error) {
+	podList, err := u.k8sPodClient.List(metav1.ListOptions{
+		LabelSelector: k8smeta.GetEnvironmentSelector(u.cfg),
+	})
+	if err != nil {
+		return nil, err
+	}
+	existingPods := map[*app]*v1.Pod{}
+	for i := 0; i < len(podList.Items); i++ {
+		pod := &podList.Items[i]
+		a := u.findAppFromObjectMeta(&pod.ObjectMeta)
+		if a != nil && u.appsToBeStarted[a] && pod.DeletionTimestamp == nil {
+			existingPods[a] = pod
+		}
+	}
+	return existingPods, nil
+}
+
+// addDependentsToRecreate adds the apps that are to be started and that (in)directly depend on an app that is recreated.
+func (u *upRunner) addDependentsToRecreate(reasons map[*app]string) {
+	for {
+		added := false
+		for a := range u.appsToBeStarted {
+			if _, ok := reasons[a]; ok {
+				continue
+			}
+			for dcService := range a.composeService.DockerComposeService.DependsOn {
+				dependency := u.getDependency(dcService)
+				if _, ok := reasons[dependency]; ok {
+					reasons[a] = fmt.Sprintf("it depends on %s", dependency.name())
+					added = true
+					break
+				}
+			}
+		}
+		if !added {
+			return
+		}
+	}
+}
+
+// sortAppsByDependencies returns apps such that each app comes after its dependencies. Apps are otherwise sorted by name.
+func (u *upRunner) sortAppsByDependencies(apps map[*app]string) []*app {
+	depths := map[*app]int{}
+	result := make([]*app, 0, len(apps))
+	for a := range apps {
+		result = append(result, a)
+		u.initDependencyDepth(a, depths)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if depths[result[i]] != depths[result[j]] {
+			return depths[result[i]] < depths[result[j]]
+		}
+		return result[i].name() < result[j].name()
+	})
+	return result
+}
+
+// initDependencyDepth computes the length of the longest chain of depends_on of an app. The docker compose configuration guarantees that
+// depends_on has no cycles.
+func (u *upRunner) initDependencyDepth(a *app, depths map[*app]int) int {
+	if depth, ok := depths[a]; ok {
+		return depth
+	}
+	depth := 0
+	for dcService := range a.composeService.DockerComposeService.DependsOn {
+		dependencyDepth := u.initDependencyDepth(u.getDependency(dcService), depths) + 1
+		if dependencyDepth > depth {
+			depth = dependencyDepth
+		}
+	}
+	depths[a] = depth
+	return depth
+}
+
+func (u *upRunner) getDependency(dcService *dockerComposeConfig.Service) *app {
+	return u.apps[u.cfg.FindService(dcService).Name]
+}
+
+// waitForPodsDeleted waits until the specified pods no longer exist. Returns the error of the context of the options when it is done.
+func (u *upRunner) waitForPodsDeleted(pods []*v1.Pod) error {
+	for _, pod := range pods {
+		err := u.waitForPodDeleted(pod)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *upRunner) waitForPodDeleted(pod *v1.Pod) error {
+	ctx, cancel := context.WithTimeout(u.opts.Context, getPodDeleteTimeout(pod))
+	defer cancel()
+	err := wait.PollImmediateUntil(podDeletePollInterval, func() (bool, error) {
+		podServer, err := u.k8sPodClient.Get(pod.Name, metav1.GetOptions{})
+		if k8sError.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return podServer.UID != pod.UID, nil
+	}, ctx.Done())
+	if err != nil {
+		if ctxErr := u.opts.Context.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("error while waiting for pod %s to be deleted: %v", pod.Name, err)
+	}
+	return nil
+}
+
+// getPodDeleteTimeout returns how long to wait for a pod to be deleted, based on its termination grace period (see
+// getTerminationGracePeriodSeconds).
+func getPodDeleteTimeout(pod *v1.Pod) time.Duration {
+	gracePeriodSeconds := int64(v1.DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriodSeconds = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return time.Duration(gracePeriodSeconds)*time.Second + podDeleteTimeoutMargin
+}
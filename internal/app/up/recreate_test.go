@@ -0,0 +1,103 @@
+package up
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPodSpecHash_Deterministic(t *testing.T) {
+	a := newTestApp("a")
+	newPod := func(image string) *v1.Pod {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Image: image,
+					},
+				},
+			},
+		}
+		pod.Labels = map[string]string{
+			"app": "a",
+			"env": "myenv",
+		}
+		return pod
+	}
+	pod1 := newPod("ubuntu:latest")
+	pod2 := newPod("ubuntu:latest")
+	pod3 := newPod("ubuntu:bionic")
+	for _, pod := range []*v1.Pod{pod1, pod2, pod3} {
+		if err := setPodSpecHash(a, pod); err != nil {
+			t.Error(err)
+		}
+	}
+	hash1 := pod1.Annotations[annotationSpecHash]
+	if hash1 == "" || hash1 != pod2.Annotations[annotationSpecHash] || hash1 == pod3.Annotations[annotationSpecHash] {
+		t.Fail()
+	}
+	a.imageInfo.sourceImageID = "sha256:1234"
+	pod4 := newPod("ubuntu:latest")
+	_ = setPodSpecHash(a, pod4)
+	if pod4.Annotations[annotationSpecHash] == hash1 {
+		t.Fail()
+	}
+}
+
+func TestAddDependentsToRecreate(t *testing.T) {
+	u := newTestUpRunner(nil, nil)
+	reasons := map[*app]string{
+		u.apps["c"]: "its spec changed",
+	}
+	u.addDependentsToRecreate(reasons)
+	if len(reasons) != 2 || reasons[u.apps["a"]] != "it depends on c" {
+		t.Error(reasons)
+	}
+}
+
+func TestSortAppsByDependencies(t *testing.T) {
+	u := newTestUpRunner(nil, nil)
+	apps := map[*app]string{}
+	for _, a := range u.apps {
+		apps[a] = ""
+	}
+	sorted := u.sortAppsByDependencies(apps)
+	var names []string
+	for _, a := range sorted {
+		names = append(names, a.name())
+	}
+	if len(names) != 4 || names[0] != "b" || names[1] != "c" || names[2] != "d" || names[3] != "a" {
+		t.Error(names)
+	}
+}
+
+func TestGetPodDeleteTimeout(t *testing.T) {
+	pod := &v1.Pod{}
+	if timeout := getPodDeleteTimeout(pod); timeout != 90*time.Second {
+		t.Error(timeout)
+	}
+	gracePeriodSeconds := int64(600)
+	pod.Spec.TerminationGracePeriodSeconds = &gracePeriodSeconds
+	if timeout := getPodDeleteTimeout(pod); timeout != 11*time.Minute {
+		t.Error(timeout)
+	}
+}
+
+func TestWaitForPodsDeleted_ContextDone(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "a-myenv",
+			Namespace: "myns",
+			UID:       "a-uid",
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	u := newTestUpRunner(&Options{Context: ctx}, nil, pod)
+	if err := u.waitForPodsDeleted([]*v1.Pod{pod}); err != context.Canceled {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,186 @@
+package up
+
+import (
+	"strings"
+
+	"github.com/kube-compose/kube-compose/internal/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Kubernetes 1.13 does not have fields for seccomp and AppArmor profiles, these are configured with annotations:
+	// https://kubernetes.io/docs/concepts/policy/pod-security-policy/#seccomp
+	// https://kubernetes.io/docs/tutorials/clusters/apparmor/
+	annotationPrefixSeccomp  = "container.seccomp.security.alpha.kubernetes.io/"
+	annotationPrefixAppArmor = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// securityOpts is the parsed form of the security_opt of a docker compose service.
+type securityOpts struct {
+	apparmorProfile string
+	noNewPrivileges bool
+	seccompProfile  string
+	seLinuxOptions  *v1.SELinuxOptions
+}
+
+// parseSecurityOpts parses the security_opt of a docker compose service. Docker accepts both a colon and an equals sign as the separator:
+// https://docs.docker.com/engine/reference/run/#security-configuration
+// Options that cannot be expressed in Kubernetes are ignored with a warning.
+func parseSecurityOpts(a *app) *securityOpts {
+	opts := &securityOpts{}
+	for _, securityOpt := range a.composeService.DockerComposeService.SecurityOpt {
+		key, value := securityOpt, ""
+		if i := strings.IndexAny(securityOpt, ":="); i >= 0 {
+			key, value = securityOpt[:i], securityOpt[i+1:]
+		}
+		switch key {
+		case "no-new-privileges":
+			opts.noNewPrivileges = value == "" || value == "true"
+		case "seccomp":
+			if value == "unconfined" {
+				opts.seccompProfile = "unconfined"
+			} else {
+				a.warnf("security_opt %#v is not supported, only seccomp profile unconfined is supported", securityOpt)
+			}
+		case "apparmor":
+			switch value {
+			case "unconfined":
+				opts.apparmorProfile = "unconfined"
+			case "docker-default":
+				opts.apparmorProfile = "runtime/default"
+			default:
+				// The profile must have been loaded on the node, like it must have been loaded on the docker host.
+				opts.apparmorProfile = "localhost/" + value
+			}
+		case "label":
+			parseSecurityOptLabel(a, opts, securityOpt, value)
+		default:
+			a.warnf("security_opt %#v is not supported, ignoring it", securityOpt)
+		}
+	}
+	return opts
+}
+
+func parseSecurityOptLabel(a *app, opts *securityOpts, securityOpt, value string) {
+	i := strings.IndexByte(value, ':')
+	if i < 0 {
+		a.warnf("security_opt %#v is not supported, ignoring it", securityOpt)
+		return
+	}
+	if opts.seLinuxOptions == nil {
+		opts.seLinuxOptions = &v1.SELinuxOptions{}
+	}
+	switch value[:i] {
+	case "user":
+		opts.seLinuxOptions.User = value[i+1:]
+	case "role":
+		opts.seLinuxOptions.Role = value[i+1:]
+	case "type":
+		opts.seLinuxOptions.Type = value[i+1:]
+	case "level":
+		opts.seLinuxOptions.Level = value[i+1:]
+	default:
+		a.warnf("security_opt %#v is not supported, ignoring it", securityOpt)
+	}
+}
+
+// createCapabilities converts the cap_add and cap_drop of a docker compose service. Docker accepts capabilities with and without the
+// CAP_ prefix, but Kubernetes expects capabilities without the prefix.
+func createCapabilities(a *app) *v1.Capabilities {
+	dcService := a.composeService.DockerComposeService
+	if len(dcService.CapAdd) == 0 && len(dcService.CapDrop) == 0 {
+		return nil
+	}
+	capabilities := &v1.Capabilities{}
+	for _, capability := range dcService.CapAdd {
+		capabilities.Add = append(capabilities.Add, toCapability(capability))
+	}
+	for _, capability := range dcService.CapDrop {
+		capabilities.Drop = append(capabilities.Drop, toCapability(capability))
+	}
+	return capabilities
+}
+
+func toCapability(capability string) v1.Capability {
+	return v1.Capability(strings.TrimPrefix(strings.ToUpper(capability), "CAP_"))
+}
+
+// createSecurityContext creates the security context of the container of a docker compose service. Returns nil if the security context
+// would be empty.
+func (u *upRunner) createSecurityContext(a *app, opts *securityOpts) *v1.SecurityContext {
+	dcService := a.composeService.DockerComposeService
+	securityContext := &v1.SecurityContext{
+		Capabilities:   createCapabilities(a),
+		SELinuxOptions: opts.seLinuxOptions,
+	}
+	if u.opts.RunAsUser {
+		securityContext.RunAsUser = a.imageInfo.user.UID
+		if a.imageInfo.user.GID != nil {
+			securityContext.RunAsGroup = a.imageInfo.user.GID
+		}
+	}
+	if dcService.Privileged {
+		securityContext.Privileged = util.NewBool(true)
+	}
+	if dcService.ReadOnly {
+		securityContext.ReadOnlyRootFilesystem = util.NewBool(true)
+	}
+	if opts.noNewPrivileges {
+		if dcService.Privileged {
+			// Kubernetes rejects privileged containers that do not allow privilege escalation.
+			a.warnf("security_opt no-new-privileges is ignored because the docker compose service is privileged")
+		} else {
+			securityContext.AllowPrivilegeEscalation = util.NewBool(false)
+		}
+	}
+	if *securityContext == (v1.SecurityContext{}) {
+		return nil
+	}
+	return securityContext
+}
+
+// createPodSecurityContext creates the security context of the pod of a docker compose service, based on its group_add and sysctls.
+// Returns nil if the security context would be empty.
+func createPodSecurityContext(a *app) *v1.PodSecurityContext {
+	var supplementalGroups []int64
+	for _, group := range a.composeService.DockerComposeService.GroupAdd {
+		gid := util.TryParseInt64(group)
+		if gid == nil || *gid < 0 {
+			a.warnf("group_add has an entry %#v that is not a group ID, group names are not supported so ignoring "+
+				"this entry", group)
+			continue
+		}
+		supplementalGroups = append(supplementalGroups, *gid)
+	}
+	sysctls := createSysctls(a)
+	if len(supplementalGroups) == 0 && len(sysctls) == 0 {
+		return nil
+	}
+	return &v1.PodSecurityContext{
+		SupplementalGroups: supplementalGroups,
+		Sysctls:            sysctls,
+	}
+}
+
+// initPodSecurity sets the security contexts and security-related annotations of the pod of a docker compose service.
+func (u *upRunner) initPodSecurity(a *app, pod *v1.Pod) {
+	if a.composeService.DockerComposeService.UsernsMode != "" {
+		a.warnf("userns_mode is not supported, ignoring it")
+	}
+	opts := parseSecurityOpts(a)
+	container := &pod.Spec.Containers[0]
+	container.SecurityContext = u.createSecurityContext(a, opts)
+	pod.Spec.SecurityContext = createPodSecurityContext(a)
+	if opts.seccompProfile == "" && opts.apparmorProfile == "" {
+		return
+	}
+	if pod.ObjectMeta.Annotations == nil {
+		pod.ObjectMeta.Annotations = map[string]string{}
+	}
+	if opts.seccompProfile != "" {
+		pod.ObjectMeta.Annotations[annotationPrefixSeccomp+container.Name] = opts.seccompProfile
+	}
+	if opts.apparmorProfile != "" {
+		pod.ObjectMeta.Annotations[annotationPrefixAppArmor+container.Name] = opts.apparmorProfile
+	}
+}
@@ -0,0 +1,110 @@
+package up
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+func newTestPod(a *app) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: a.composeService.NameEscaped,
+				},
+			},
+		},
+	}
+}
+
+func TestInitPodSecurity_Empty(t *testing.T) {
+	u := &upRunner{
+		opts: &Options{},
+	}
+	a := newTestApp("a")
+	pod := newTestPod(a)
+	u.initPodSecurity(a, pod)
+	if pod.Spec.Containers[0].SecurityContext != nil || pod.Spec.SecurityContext != nil || pod.ObjectMeta.Annotations != nil {
+		t.Error(pod)
+	}
+}
+
+func TestInitPodSecurity_Success(t *testing.T) {
+	u := &upRunner{
+		opts: &Options{},
+	}
+	a := newTestApp("a")
+	dcService := a.composeService.DockerComposeService
+	dcService.CapAdd = []string{"CAP_NET_ADMIN", "sys_time"}
+	dcService.CapDrop = []string{"ALL"}
+	dcService.GroupAdd = []string{"1000", "mail"}
+	dcService.ReadOnly = true
+	dcService.SecurityOpt = []string{
+		"no-new-privileges:true",
+		"seccomp=unconfined",
+		"apparmor:docker-default",
+		"label=user:USER",
+		"label:level:s0:c100,c200",
+		"unknown",
+	}
+	dcService.UsernsMode = "host"
+	pod := newTestPod(a)
+	u.initPodSecurity(a, pod)
+	expected := &v1.SecurityContext{
+		Capabilities: &v1.Capabilities{
+			Add:  []v1.Capability{"NET_ADMIN", "SYS_TIME"},
+			Drop: []v1.Capability{"ALL"},
+		},
+		SELinuxOptions: &v1.SELinuxOptions{
+			User:  "USER",
+			Level: "s0:c100,c200",
+		},
+		ReadOnlyRootFilesystem:   util.NewBool(true),
+		AllowPrivilegeEscalation: util.NewBool(false),
+	}
+	if !reflect.DeepEqual(pod.Spec.Containers[0].SecurityContext, expected) {
+		t.Error(pod.Spec.Containers[0].SecurityContext)
+	}
+	expectedPodSecurityContext := &v1.PodSecurityContext{
+		SupplementalGroups: []int64{1000},
+	}
+	if !reflect.DeepEqual(pod.Spec.SecurityContext, expectedPodSecurityContext) {
+		t.Error(pod.Spec.SecurityContext)
+	}
+	expectedAnnotations := map[string]string{
+		annotationPrefixSeccomp + "a":  "unconfined",
+		annotationPrefixAppArmor + "a": "runtime/default",
+	}
+	if !reflect.DeepEqual(pod.ObjectMeta.Annotations, expectedAnnotations) {
+		t.Error(pod.ObjectMeta.Annotations)
+	}
+}
+
+func TestInitPodSecurity_PrivilegedNoNewPrivileges(t *testing.T) {
+	u := &upRunner{
+		opts: &Options{},
+	}
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.Privileged = true
+	a.composeService.DockerComposeService.SecurityOpt = []string{"no-new-privileges"}
+	pod := newTestPod(a)
+	u.initPodSecurity(a, pod)
+	expected := &v1.SecurityContext{
+		Privileged: util.NewBool(true),
+	}
+	if !reflect.DeepEqual(pod.Spec.Containers[0].SecurityContext, expected) {
+		t.Error(pod.Spec.Containers[0].SecurityContext)
+	}
+}
+
+func TestParseSecurityOpts_AppArmorLocalhost(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.DockerComposeService.SecurityOpt = []string{"apparmor=my-profile", "no-new-privileges=false"}
+	opts := parseSecurityOpts(a)
+	if opts.apparmorProfile != "localhost/my-profile" || opts.noNewPrivileges {
+		t.Error(opts)
+	}
+}
@@ -0,0 +1,123 @@
+package up
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getPodToCreate returns the stored pod of an app when starting, and otherwise builds the pod and stores it so that it can be started
+// again after it is stopped.
+func (u *upRunner) getPodToCreate(a *app) (*v1.Pod, error) {
+	if u.storedPods != nil {
+		return u.storedPods[a].DeepCopy(), nil
+	}
+	pod, err := u.buildPod(a)
+	if err != nil {
+		return nil, err
+	}
+	return pod, u.storePod(a, pod)
+}
+
+// storedPodKey is the key of the data of the ConfigMap of a stored pod.
+const storedPodKey = "pod.json"
+
+// getStoredPodName returns the name of the ConfigMap that stores the pod with the specified name. Each pod is stored in its own ConfigMap,
+// because the size of a ConfigMap is limited to 1 MiB.
+func getStoredPodName(podName string) string {
+	return "kube-compose-pod-" + podName
+}
+
+// storePod stores a pod in a ConfigMap that is owned by the anchor of the environment, so that the ConfigMap is deleted with the
+// environment.
+func (u *upRunner) storePod(a *app, pod *v1.Pod) error {
+	if u.anchorOwnerReference == nil {
+		return nil
+	}
+	podJSON, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	configMap := &v1.ConfigMap{
+		Data: map[string]string{
+			storedPodKey: string(podJSON),
+		},
+	}
+	k8smeta.InitObjectMeta(u.cfg, &configMap.ObjectMeta, a.composeService)
+	configMap.Name = getStoredPodName(pod.Name)
+	u.initOwnerReferences(&configMap.ObjectMeta)
+	configMapClient := u.k8sClientset.CoreV1().ConfigMaps(u.cfg.Namespace)
+	_, err = configMapClient.Create(configMap)
+	if !k8sError.IsAlreadyExists(err) {
+		return err
+	}
+	configMapServer, err := configMapClient.Get(configMap.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if !k8smeta.IsOwnedBy(&configMapServer.ObjectMeta, u.anchorOwnerReference.UID) {
+		return fmt.Errorf("configmap %s already exists and was not created by kube-compose for this environment", configMap.Name)
+	}
+	configMapServer.Data = configMap.Data
+	_, err = configMapClient.Update(configMapServer)
+	return err
+}
+
+// loadStoredPods loads the stored pods of the apps that are to be started.
+func (u *upRunner) loadStoredPods() error {
+	configMapClient := u.k8sClientset.CoreV1().ConfigMaps(u.cfg.Namespace)
+	anchorName := k8smeta.GetAnchorName(u.cfg)
+	anchor, err := configMapClient.Get(anchorName, metav1.GetOptions{})
+	if k8sError.IsNotFound(err) {
+		return fmt.Errorf("the environment does not exist, use up to create it")
+	}
+	if err != nil {
+		return err
+	}
+	if !k8smeta.IsManagedObjectOfEnvironment(u.cfg, &anchor.ObjectMeta) {
+		return fmt.Errorf("configmap %s was not created by kube-compose for this environment", anchorName)
+	}
+	u.storedPods = map[*app]*v1.Pod{}
+	for a := range u.appsToBeStarted {
+		name := getStoredPodName(k8smeta.GetK8sName(a.composeService, u.cfg))
+		configMap, err := configMapClient.Get(name, metav1.GetOptions{})
+		if k8sError.IsNotFound(err) || (err == nil && !k8smeta.IsOwnedBy(&configMap.ObjectMeta, anchor.UID)) {
+			return fmt.Errorf("app %s: the pod has never been created, use up to create it", a.name())
+		}
+		if err != nil {
+			return err
+		}
+		pod := &v1.Pod{}
+		err = json.Unmarshal([]byte(configMap.Data[storedPodKey]), pod)
+		if err != nil {
+			return fmt.Errorf("app %s: the stored pod is invalid: %v", a.name(), err)
+		}
+		u.storedPods[a] = pod
+	}
+	return nil
+}
+
+func (u *upRunner) runStart() error {
+	u.initApps()
+	u.initAppsToBeStarted()
+	err := u.initKubernetesClientset()
+	if err != nil {
+		return err
+	}
+	err = u.loadStoredPods()
+	if err != nil {
+		return err
+	}
+	return u.runCreatePods()
+}
+
+// Start recreates the pods of an environment that were deleted by stop, using the pods stored by up and in an order that respects
+// depends_on. Unlike Run, Start does not build images or apply changes to the docker compose file.
+func Start(cfg *config.Config, opts *Options) error {
+	return newUpRunner(cfg, opts).runStart()
+}
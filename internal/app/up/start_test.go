@@ -0,0 +1,101 @@
+package up
+
+import (
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestAnchor() *v1.ConfigMap {
+	u := newTestUpRunner(nil, []string{"b"})
+	anchor := &v1.ConfigMap{}
+	k8smeta.InitAnchorObjectMeta(u.cfg, &anchor.ObjectMeta)
+	anchor.Namespace = u.cfg.Namespace
+	anchor.UID = "anchor-uid"
+	return anchor
+}
+
+func TestStorePod_LoadStoredPodsSuccess(t *testing.T) {
+	u := newTestUpRunner(nil, []string{"b"}, newTestAnchor())
+	err := u.createAnchor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &v1.Pod{}
+	pod.Name = "b-myenv"
+	pod.Spec.RestartPolicy = v1.RestartPolicyNever
+	err = u.storePod(u.apps["b"], pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Storing the pod again updates the ConfigMap.
+	pod.Spec.RestartPolicy = v1.RestartPolicyAlways
+	err = u.storePod(u.apps["b"], pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.loadStoredPods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	podToCreate, err := u.getPodToCreate(u.apps["b"])
+	if err != nil {
+		t.Error(err)
+	} else if podToCreate.Name != "b-myenv" || podToCreate.Spec.RestartPolicy != v1.RestartPolicyAlways {
+		t.Error(podToCreate)
+	}
+}
+
+func TestStorePod_NotOwnedError(t *testing.T) {
+	configMap := &v1.ConfigMap{}
+	configMap.Name = getStoredPodName("b-myenv")
+	configMap.Namespace = "myns"
+	u := newTestUpRunner(nil, []string{"b"}, newTestAnchor(), configMap)
+	err := u.createAnchor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &v1.Pod{}
+	pod.Name = "b-myenv"
+	err = u.storePod(u.apps["b"], pod)
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestLoadStoredPods_EnvironmentMissingError(t *testing.T) {
+	u := newTestUpRunner(nil, []string{"b"})
+	err := u.loadStoredPods()
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestLoadStoredPods_MissingError(t *testing.T) {
+	u := newTestUpRunner(nil, []string{"b"}, newTestAnchor())
+	err := u.loadStoredPods()
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestLoadStoredPods_InvalidError(t *testing.T) {
+	anchor := newTestAnchor()
+	configMap := &v1.ConfigMap{
+		Data: map[string]string{
+			storedPodKey: "{",
+		},
+	}
+	configMap.Name = getStoredPodName("b-myenv")
+	configMap.Namespace = "myns"
+	configMap.OwnerReferences = []metav1.OwnerReference{
+		k8smeta.NewAnchorOwnerReference(&anchor.ObjectMeta),
+	}
+	u := newTestUpRunner(nil, []string{"b"}, anchor, configMap)
+	err := u.loadStoredPods()
+	if err == nil {
+		t.Fail()
+	}
+}
@@ -2,8 +2,11 @@ package up
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 
@@ -38,6 +41,7 @@ type appImageInfo struct {
 	podImagePullPolicy v1.PullPolicy
 	sourceImageID      string
 	cmd                []string
+	entrypoint         []string
 	user               *docker.Userinfo
 }
 
@@ -62,6 +66,7 @@ type app struct {
 	maxObservedPodStatus                 podStatus
 	containersForWhichWeAreStreamingLogs map[string]bool
 	color                                cmdColor.Color
+	events                               config.EventHandler
 	volumes                              []*appVolume
 	volumeInitImage                      appVolumesInitImage
 }
@@ -70,8 +75,23 @@ func (a *app) name() string {
 	return a.composeService.Name
 }
 
+// infof reports progress of the app (see config.EventHandler).
+func (a *app) infof(format string, args ...interface{}) {
+	a.events.Infof(a.name(), format, args...)
+}
+
+// warnf reports that (part of) the docker compose service of the app is ignored or not fully supported (see config.EventHandler).
+func (a *app) warnf(format string, args ...interface{}) {
+	a.events.Warnf(a.name(), format, args...)
+}
+
 func (a *app) hasService() bool {
-	return len(a.composeService.Ports) > 0
+	return len(a.composeService.Ports) > 0 || a.composeService.Headless
+}
+
+// hasClusterIP returns true if and only if the Kubernetes service of the app is assigned a cluster IP.
+func (a *app) hasClusterIP() bool {
+	return a.hasService() && !a.composeService.Headless
 }
 
 type hostAliases struct {
@@ -88,33 +108,52 @@ type localImagesCache struct {
 }
 
 type upRunner struct {
+	anchorOwnerReference  *metav1.OwnerReference
 	apps                  map[string]*app
 	appsThatNeedToBeReady map[*app]bool
 	appsToBeStarted       map[*app]bool
 	cfg                   *config.Config
 	completedChannels     []chan interface{}
-	dockerClient          *dockerClient.Client
-	k8sClientset          *kubernetes.Clientset
+	dockerClient          DockerClient
+	k8sClientset          kubernetes.Interface
 	k8sServiceClient      clientV1.ServiceInterface
 	k8sPodClient          clientV1.PodInterface
 	hostAliases           hostAliases
 	localImagesCache      localImagesCache
 	maxServiceNameLength  int
 	opts                  *Options
+	storedPods            map[*app]*v1.Pod
 	totalVolumeCount      int
 }
 
 func (u *upRunner) initKubernetesClientset() error {
-	k8sClientset, err := kubernetes.NewForConfig(u.cfg.KubeConfig)
-	if err != nil {
-		return err
+	if u.opts.KubernetesClient != nil {
+		u.k8sClientset = u.opts.KubernetesClient
+	} else {
+		k8sClientset, err := kubernetes.NewForConfig(u.cfg.KubeConfig)
+		if err != nil {
+			return err
+		}
+		u.k8sClientset = k8sClientset
 	}
-	u.k8sClientset = k8sClientset
 	u.k8sServiceClient = u.k8sClientset.CoreV1().Services(u.cfg.Namespace)
 	u.k8sPodClient = u.k8sClientset.CoreV1().Pods(u.cfg.Namespace)
 	return nil
 }
 
+func (u *upRunner) initDockerClient() error {
+	if u.opts.DockerClient != nil {
+		u.dockerClient = u.opts.DockerClient
+		return nil
+	}
+	dc, err := dockerClient.NewEnvClient()
+	if err != nil {
+		return err
+	}
+	u.dockerClient = dc
+	return nil
+}
+
 func (u *upRunner) initAppsToBeStarted() {
 	u.appsToBeStarted = map[*app]bool{}
 	colorIndex := 0
@@ -137,7 +176,7 @@ func (u *upRunner) initAppsToBeStarted() {
 
 func (u *upRunner) initVolumeInfoWarnOnce(s string) {
 	if u.totalVolumeCount == 1 {
-		fmt.Println(s)
+		u.cfg.EventHandler.Warnf("", "%s", s)
 	}
 }
 
@@ -150,21 +189,21 @@ func (u *upRunner) initVolumeInfo() {
 			}
 			u.totalVolumeCount++
 			if u.totalVolumeCount == 2 {
-				fmt.Printf("WARNING: the docker compose configuration potentially has a volume that is projected into the file system f1" +
-					" and f2 of containers c1 and c2, respectively, but currently changes in f1 will not be reflected in f2 (see " +
-					"https://github.com/kube-compose/kube-compose#limitations)\n")
+				u.cfg.EventHandler.Warnf("", "the docker compose configuration potentially has a volume that is projected into the file "+
+					"system f1 and f2 of containers c1 and c2, respectively, but currently changes in f1 will not be reflected in f2 (see "+
+					"https://github.com/kube-compose/kube-compose#limitations)")
 			}
-			u.initVolumeInfoWarnOnce("WARNING: the docker compose configuration has one or more bind volumes, but the current implementation " +
+			u.initVolumeInfoWarnOnce("the docker compose configuration has one or more bind volumes, but the current implementation " +
 				"cannot reflect changes on the host file system in containers (and vice versa, see " +
 				"https://github.com/kube-compose/kube-compose#limitations)")
 			flag := false
 			if u.cfg.ClusterImageStorage.Docker == nil && u.cfg.ClusterImageStorage.DockerRegistry == nil {
-				u.initVolumeInfoWarnOnce("WARNING: the docker compose configuration has one or more bind volumes, but they have been disabled " +
+				u.initVolumeInfoWarnOnce("the docker compose configuration has one or more bind volumes, but they have been disabled " +
 					"because the configuration to push images is missing (see https://github.com/kube-compose/kube-compose#volumes)")
 				flag = true
 			}
 			if u.cfg.VolumeInitBaseImage == nil {
-				u.initVolumeInfoWarnOnce("WARNING: the docker compose configuration has one or more bind volumes, but they have been disabled " +
+				u.initVolumeInfoWarnOnce("the docker compose configuration has one or more bind volumes, but they have been disabled " +
 					"because the base image of volume init containers is not configured (see " +
 					"https://github.com/kube-compose/kube-compose#volumes)")
 				flag = true
@@ -189,9 +228,8 @@ func initVolumeInfoGetAppVolume(a *app, serviceVolume dockerComposeConfig.Servic
 				r.readOnly = true
 			case "rw":
 			default:
-				fmt.Printf(
-					"app %s: docker compose service has a volume with an invalid mode %#v, ignoring this volume\n",
-					a.name(),
+				a.warnf(
+					"docker compose service has a volume with an invalid mode %#v, ignoring this volume",
 					serviceVolume.Short.Mode,
 				)
 				return nil
@@ -201,10 +239,9 @@ func initVolumeInfoGetAppVolume(a *app, serviceVolume dockerComposeConfig.Servic
 			var err error
 			r.resolvedHostPath, err = resolveBindVolumeHostPath(serviceVolume.Short.HostPath)
 			if err != nil {
-				fmt.Printf(
-					"app %s: docker compose service has a volume with host path %#v, ignoring this volume because resolving the "+
-						"host path resulted in an error: %v\n",
-					a.name(),
+				a.warnf(
+					"docker compose service has a volume with host path %#v, ignoring this volume because resolving the "+
+						"host path resulted in an error: %v",
 					serviceVolume.Short.HostPath,
 					err,
 				)
@@ -262,8 +299,11 @@ func (u *upRunner) pushImage(sourceImageID, name, tag, imageDescr string, a *app
 		return
 	}
 	var digest string
-	digest, err = pushImageWithLogging(u.opts.Context, u.dockerClient, a.name(), imagePush, u.cfg.KubeConfig.BearerToken,
-		imageDescr)
+	var bearerToken string
+	if u.cfg.KubeConfig != nil {
+		bearerToken = u.cfg.KubeConfig.BearerToken
+	}
+	digest, err = pushImageWithLogging(u.opts.Context, u.dockerClient, a, imagePush, bearerToken, imageDescr)
 	if err != nil {
 		return
 	}
@@ -285,6 +325,7 @@ func (u *upRunner) initApps() {
 		app := &app{
 			composeService:                       composeService,
 			containersForWhichWeAreStreamingLogs: make(map[string]bool),
+			events:                               u.cfg.EventHandler,
 		}
 		app.imageInfo.once = &sync.Once{}
 		app.volumeInitImage.once = &sync.Once{}
@@ -316,6 +357,7 @@ func (u *upRunner) getAppImageInfo(app *app) error {
 		return err
 	}
 	app.imageInfo.cmd = inspect.Config.Cmd
+	app.imageInfo.entrypoint = inspect.Config.Entrypoint
 	err = u.getAppImageEnsureCorrectPodImage(app, sourceImageRef, sourceImage)
 	if err != nil {
 		return err
@@ -370,7 +412,7 @@ func (u *upRunner) getAppImageInfoEnsureSourceImageID(sourceImage string, source
 		if !sourceImageIsNamed {
 			return fmt.Errorf("could not find image %#v locally, and building images is not supported", sourceImage)
 		}
-		digest, err := pullImageWithLogging(u.opts.Context, u.dockerClient, a.name(), sourceImageRef.String())
+		digest, err := pullImageWithLogging(u.opts.Context, u.dockerClient, a, sourceImageRef.String())
 		if err != nil {
 			return err
 		}
@@ -406,7 +448,7 @@ func (u *upRunner) getAppImageInfoUser(a *app, inspect *dockerTypes.ImageInspect
 	if user.UID == nil || (user.Group != "" && user.GID == nil) {
 		// TODO https://github.com/kube-compose/kube-compose/issues/70 confirm whether docker and our pod spec will produce the same default
 		// group if a UID is set but no GID
-		err := getUserinfoFromImage(u.opts.Context, u.dockerClient, a.imageInfo.sourceImageID, user)
+		err := getUserinfoFromImage(u.opts.Context, u.dockerClient, a, a.imageInfo.sourceImageID, user)
 		if err != nil {
 			return errors.Wrapf(err, "error getting uid/gid from image %#v", sourceImage)
 		}
@@ -445,7 +487,7 @@ func (u *upRunner) waitForServiceClusterIPUpdate(service *v1.Service) (*app, err
 func (u *upRunner) waitForServiceClusterIPCountRemaining() int {
 	remaining := 0
 	for _, app := range u.apps {
-		if app.hasService() && app.serviceClusterIP == "" {
+		if app.hasClusterIP() && app.serviceClusterIP == "" {
 			remaining++
 		}
 	}
@@ -491,7 +533,13 @@ func (u *upRunner) waitForServiceClusterIPWatchEvent(event *k8swatch.Event) erro
 
 func (u *upRunner) waitForServiceClusterIPWatch(expected, remaining int, eventChannel <-chan k8swatch.Event) error {
 	for {
-		event, ok := <-eventChannel
+		var event k8swatch.Event
+		var ok bool
+		select {
+		case event, ok = <-eventChannel:
+		case <-u.opts.Context.Done():
+			return u.opts.Context.Err()
+		}
 		if !ok {
 			return fmt.Errorf("channel unexpectedly closed")
 		}
@@ -502,7 +550,7 @@ func (u *upRunner) waitForServiceClusterIPWatch(expected, remaining int, eventCh
 		remainingNew := u.waitForServiceClusterIPCountRemaining()
 		if remainingNew != remaining {
 			remaining = remainingNew
-			fmt.Printf("waiting for cluster IP assignment (%d/%d)\n", expected-remaining, expected)
+			u.cfg.EventHandler.Infof("", "waiting for cluster IP assignment (%d/%d)", expected-remaining, expected)
 			if remaining == 0 {
 				break
 			}
@@ -513,14 +561,14 @@ func (u *upRunner) waitForServiceClusterIPWatch(expected, remaining int, eventCh
 
 func (u *upRunner) waitForServiceClusterIP(expected int) error {
 	listOptions := metav1.ListOptions{
-		LabelSelector: u.cfg.EnvironmentLabel + "=" + u.cfg.EnvironmentID,
+		LabelSelector: k8smeta.GetEnvironmentSelector(u.cfg),
 	}
 	resourceVersion, err := u.waitForServiceClusterIPList(expected, &listOptions)
 	if err != nil {
 		return err
 	}
 	remaining := u.waitForServiceClusterIPCountRemaining()
-	fmt.Printf("waiting for cluster IP assignment (%d/%d)\n", expected-remaining, expected)
+	u.cfg.EventHandler.Infof("", "waiting for cluster IP assignment (%d/%d)", expected-remaining, expected)
 	if remaining == 0 {
 		return nil
 	}
@@ -540,14 +588,16 @@ func (u *upRunner) createServicesAndGetPodHostAliases() ([]v1.HostAlias, error)
 		if !app.hasService() {
 			continue
 		}
-		expectedServiceCount++
-		servicePorts := make([]v1.ServicePort, len(app.composeService.DockerComposeService.Ports))
-		for i, port := range app.composeService.DockerComposeService.Ports {
+		servicePorts := make([]v1.ServicePort, len(app.composeService.Ports))
+		for i, port := range app.composeService.Ports {
 			servicePorts[i] = v1.ServicePort{
-				Name:       fmt.Sprintf("%s%d", port.Protocol, port.Internal),
-				Port:       port.Internal,
+				Name:       port.Name,
+				Port:       port.Port,
 				Protocol:   v1.Protocol(strings.ToUpper(port.Protocol)),
-				TargetPort: intstr.FromInt(int(port.Internal)),
+				TargetPort: intstr.FromInt(int(port.Port)),
+			}
+			if port.Name == "" {
+				servicePorts[i].Name = fmt.Sprintf("%s%d", port.Protocol, port.Port)
 			}
 		}
 		service := &v1.Service{
@@ -557,21 +607,57 @@ func (u *upRunner) createServicesAndGetPodHostAliases() ([]v1.HostAlias, error)
 				Type:     v1.ServiceType("ClusterIP"),
 			},
 		}
+		if app.hasClusterIP() {
+			expectedServiceCount++
+		} else {
+			service.Spec.ClusterIP = v1.ClusterIPNone
+			service.Spec.PublishNotReadyAddresses = true
+		}
 		k8smeta.InitObjectMeta(u.cfg, &service.ObjectMeta, app.composeService)
+		initServiceAppProtocols(app, service)
+		u.initOwnerReferences(&service.ObjectMeta)
 		_, err := u.k8sServiceClient.Create(service)
 		switch {
 		case k8sError.IsAlreadyExists(err):
-			fmt.Printf("app %s: service %s already exists\n", app.name(), service.ObjectMeta.Name)
+			app.infof("service %s already exists", service.ObjectMeta.Name)
 		case err != nil:
 			return nil, err
 		default:
-			fmt.Printf("app %s: service %s created\n", app.name(), service.ObjectMeta.Name)
+			app.infof("service %s created", service.ObjectMeta.Name)
 		}
 	}
-	if expectedServiceCount == 0 {
-		return nil, nil
+	if u.cfg.ServiceDiscovery == config.ServiceDiscoveryDNS {
+		err := u.createSubdomainService()
+		if err != nil {
+			return nil, err
+		}
 	}
-	return u.getPodHostAliasesCore(expectedServiceCount)
+	for _, app := range u.apps {
+		if u.needsHostAlias(app) {
+			return u.getPodHostAliasesCore(expectedServiceCount)
+		}
+	}
+	// If service discovery is based on DNS then pods resolve each other through the subdomain, so there is no need to wait for cluster
+	// IPs.
+	return nil, nil
+}
+
+// initServiceAppProtocols adds the application protocols of the ports of a Kubernetes service as an annotation, which maps the names of
+// the ports to their application protocols. The Kubernetes API version that kube-compose is built against does not have the appProtocol
+// field of service ports.
+func initServiceAppProtocols(a *app, service *v1.Service) {
+	appProtocols := map[string]string{}
+	for i, port := range a.composeService.Ports {
+		if port.AppProtocol != "" {
+			appProtocols[service.Spec.Ports[i].Name] = port.AppProtocol
+		}
+	}
+	if len(appProtocols) == 0 {
+		return
+	}
+	// Marshaling a map of strings cannot fail, and the keys are sorted.
+	data, _ := json.Marshal(appProtocols)
+	service.Annotations[k8smeta.AppProtocolsAnnotationName] = string(data)
 }
 
 func (u *upRunner) getPodHostAliasesCore(expectedServiceCount int) ([]v1.HostAlias, error) {
@@ -579,19 +665,20 @@ func (u *upRunner) getPodHostAliasesCore(expectedServiceCount int) ([]v1.HostAli
 	if err != nil {
 		return nil, err
 	}
-	hostAliases := make([]v1.HostAlias, expectedServiceCount)
-	i := 0
+	var hostAliases []v1.HostAlias
 	for _, app := range u.apps {
-		if app.hasService() {
-			hostAliases[i] = v1.HostAlias{
+		if u.needsHostAlias(app) {
+			hostAliases = append(hostAliases, v1.HostAlias{
 				IP: app.serviceClusterIP,
 				Hostnames: []string{
 					app.name(),
 				},
-			}
-			i++
+			})
 		}
 	}
+	sort.Slice(hostAliases, func(i, j int) bool {
+		return hostAliases[i].Hostnames[0] < hostAliases[j].Hostnames[0]
+	})
 	return hostAliases, nil
 }
 
@@ -668,7 +755,7 @@ func (a *app) GetArgsAndCommand(c *v1.Container) error {
 	// docker-compose does not ignore the entrypoint if it is an empty array. For example: if the entrypoint is empty but the command is not
 	// empty then the entrypoint becomes the command. But the Kubernetes client treats an empty entrypoint array as an unset entrypoint,
 	// consequently the image's entrypoint will be used. This if-else statement bridges the gap in behavior.
-	if a.composeService.DockerComposeService.EntrypointPresent && len(a.composeService.DockerComposeService.Entrypoint) == 0 {
+	if a.composeService.DockerComposeService.Entrypoint != nil && len(a.composeService.DockerComposeService.Entrypoint) == 0 {
 		c.Command = a.composeService.DockerComposeService.Command
 		if len(c.Command) == 0 {
 			c.Command = a.imageInfo.cmd
@@ -683,23 +770,6 @@ func (a *app) GetArgsAndCommand(c *v1.Container) error {
 	return nil
 }
 
-func (u *upRunner) createSecurityContext(a *app) *v1.SecurityContext {
-	if u.opts.RunAsUser || a.composeService.DockerComposeService.Privileged {
-		securityContext := &v1.SecurityContext{}
-		if u.opts.RunAsUser {
-			securityContext.RunAsUser = a.imageInfo.user.UID
-			if a.imageInfo.user.GID != nil {
-				securityContext.RunAsGroup = a.imageInfo.user.GID
-			}
-		}
-		if a.composeService.DockerComposeService.Privileged {
-			securityContext.Privileged = util.NewBool(true)
-		}
-		return securityContext
-	}
-	return nil
-}
-
 func (u *upRunner) createPodVolumes(a *app, pod *v1.Pod) error {
 	if len(a.volumes) == 0 {
 		return nil
@@ -731,7 +801,7 @@ func (u *upRunner) createPodVolumes(a *app, pod *v1.Pod) error {
 		})
 	}
 	initContainer := v1.Container{
-		Name:            a.composeService.NameEscaped + "-init",
+		Name:            k8smeta.ShortenName(a.composeService.NameEscaped + "-init"),
 		Image:           a.volumeInitImage.podImage,
 		ImagePullPolicy: a.volumeInitImage.podImagePullPolicy,
 		VolumeMounts:    initVolumeMounts,
@@ -742,7 +812,8 @@ func (u *upRunner) createPodVolumes(a *app, pod *v1.Pod) error {
 	return nil
 }
 
-func (u *upRunner) createPod(app *app) (*v1.Pod, error) {
+// buildPod returns the pod of an app as it should be created.
+func (u *upRunner) buildPod(app *app) (*v1.Pod, error) {
 	err := u.getAppImageInfoOnce(app)
 	if err != nil {
 		return nil, err
@@ -768,6 +839,10 @@ func (u *upRunner) createPod(app *app) (*v1.Pod, error) {
 			}
 			i++
 		}
+		// Sort the environment variables so that the pod spec, and therefore its hash, is deterministic.
+		sort.Slice(envVars, func(i, j int) bool {
+			return envVars[i].Name < envVars[j].Name
+		})
 	}
 	hostAliases, err := u.createServicesAndGetPodHostAliasesOnce()
 	if err != nil {
@@ -783,31 +858,60 @@ func (u *upRunner) createPod(app *app) (*v1.Pod, error) {
 					Env:             envVars,
 					Image:           app.imageInfo.podImage,
 					ImagePullPolicy: app.imageInfo.podImagePullPolicy,
-					Name:            app.composeService.NameEscaped,
+					Lifecycle:       createLifecycle(app),
+					Name:            k8smeta.ShortenName(app.composeService.NameEscaped),
 					Ports:           containerPorts,
 					ReadinessProbe:  readinessProbe,
-					SecurityContext: u.createSecurityContext(app),
 					WorkingDir:      app.composeService.DockerComposeService.WorkingDir,
 				},
 			},
-			HostAliases:   hostAliases,
-			RestartPolicy: getRestartPolicyforService(app),
+			DNSConfig:                     u.createPodDNSConfig(app),
+			HostAliases:                   getPodHostAliases(app, hostAliases),
+			RestartPolicy:                 getRestartPolicyforService(app),
+			TerminationGracePeriodSeconds: getTerminationGracePeriodSeconds(app),
 		},
 	}
+	initPodInit(app, &pod.Spec)
+	initPodSpecOverrides(app, &pod.Spec)
+	u.initPodSecurity(app, pod)
+	u.initPodHostnameAndSubdomain(app, &pod.Spec)
 	err = app.GetArgsAndCommand(&pod.Spec.Containers[0])
 	if err != nil {
 		return nil, err
 	}
-	k8smeta.InitObjectMeta(u.cfg, &pod.ObjectMeta, app.composeService)
+	err = app.wrapCommandWithUlimits(&pod.Spec.Containers[0])
+	if err != nil {
+		return nil, err
+	}
 
 	err = u.createPodVolumes(app, pod)
 	if err != nil {
 		return nil, err
 	}
+	initPodShm(app, pod)
+	err = applyPodPatch(app, pod)
+	if err != nil {
+		return nil, err
+	}
+	// The metadata is initialized after applying the pod_patch, so that the patch cannot change the name, labels and annotations managed
+	// by kube-compose.
+	k8smeta.InitObjectMeta(u.cfg, &pod.ObjectMeta, app.composeService)
+	err = setPodSpecHash(app, pod)
+	if err != nil {
+		return nil, err
+	}
+	u.initOwnerReferences(&pod.ObjectMeta)
+	return pod, nil
+}
 
+func (u *upRunner) createPod(app *app) (*v1.Pod, error) {
+	pod, err := u.getPodToCreate(app)
+	if err != nil {
+		return nil, err
+	}
 	podServer, err := u.k8sPodClient.Create(pod)
 	if k8sError.IsAlreadyExists(err) {
-		fmt.Printf("app %s: pod %s already exists\n", app.name(), pod.ObjectMeta.Name)
+		app.infof("pod %s already exists", pod.ObjectMeta.Name)
 	} else if err != nil {
 		return nil, err
 	}
@@ -898,28 +1002,39 @@ func (u *upRunner) updateAppMaxObservedPodStatus(pod *v1.Pod) error {
 
 	if s > app.maxObservedPodStatus {
 		app.maxObservedPodStatus = s
-		fmt.Printf("app %s: pod status %s\n", app.name(), &app.maxObservedPodStatus)
+		app.infof("pod status %s", &app.maxObservedPodStatus)
 	}
 
 	return nil
 }
 
+// streamPodLogs streams the logs of a container of a pod until the container terminates or the context of the options is done. Errors are
+// reported as warnings, because the logs are not needed to bring up the pods.
 func (u *upRunner) streamPodLogs(pod *v1.Pod, completedChannel chan interface{}, getPodLogOptions *v1.PodLogOptions, a *app) {
-	getLogsRequest := u.k8sPodClient.GetLogs(pod.ObjectMeta.Name, getPodLogOptions)
+	defer close(completedChannel)
+	getLogsRequest := u.k8sPodClient.GetLogs(pod.ObjectMeta.Name, getPodLogOptions).Context(u.opts.Context)
 	var bodyReader io.ReadCloser
 	bodyReader, err := getLogsRequest.Stream()
 	if err != nil {
-		panic(err)
+		a.warnf("could not stream the logs of pod %s: %v", pod.ObjectMeta.Name, err)
+		return
 	}
 	defer util.CloseAndLogError(bodyReader)
 	scanner := bufio.NewScanner(bodyReader)
 	for scanner.Scan() {
-		fmt.Printf("%-*s| %s\n", u.maxServiceNameLength+3, cmdColor.Colorize(a.name(), a.color), scanner.Text())
+		if a.events == nil {
+			fmt.Printf("%-*s| %s\n", u.maxServiceNameLength+3, cmdColor.Colorize(a.name(), a.color), scanner.Text())
+			continue
+		}
+		a.events.Handle(&config.Event{
+			Type:    config.EventLog,
+			Service: a.name(),
+			Message: scanner.Text(),
+		})
 	}
-	if err = scanner.Err(); err != nil {
-		fmt.Println(err)
+	if err = scanner.Err(); err != nil && u.opts.Context.Err() == nil {
+		a.warnf("error while streaming the logs of pod %s: %v", pod.ObjectMeta.Name, err)
 	}
-	close(completedChannel)
 }
 
 func (u *upRunner) createPodsIfNeeded() error {
@@ -944,7 +1059,7 @@ func (u *upRunner) createPodsIfNeeded() error {
 				return err
 			}
 			reason := u.formatCreatePodReason(app1)
-			fmt.Printf("app %s: created pod %s because %s\n", app1.name(), pod.ObjectMeta.Name, reason)
+			app1.infof("created pod %s because %s", pod.ObjectMeta.Name, reason)
 			delete(u.appsToBeStarted, app1)
 		}
 	}
@@ -981,7 +1096,7 @@ func (u *upRunner) runStartInitialPods() error {
 		if err != nil {
 			return err
 		}
-		fmt.Printf("app %s: created pod %s because all its dependency conditions are met\n", app.name(), pod.ObjectMeta.Name)
+		app.infof("created pod %s because all its dependency conditions are met", pod.ObjectMeta.Name)
 		delete(u.appsToBeStarted, app)
 	}
 	return nil
@@ -989,7 +1104,7 @@ func (u *upRunner) runStartInitialPods() error {
 
 func (u *upRunner) runListPodsAndCreateThemIfNeeded() (string, error) {
 	listOptions := metav1.ListOptions{
-		LabelSelector: u.cfg.EnvironmentLabel + "=" + u.cfg.EnvironmentID,
+		LabelSelector: k8smeta.GetEnvironmentSelector(u.cfg),
 	}
 	podList, err := u.k8sPodClient.List(listOptions)
 	if err != nil {
@@ -1016,13 +1131,22 @@ func (u *upRunner) run() error {
 	if err != nil {
 		return err
 	}
-	// Initialize docker client
-	var dc *dockerClient.Client
-	dc, err = dockerClient.NewEnvClient()
+	err = u.createNamespace()
+	if err != nil {
+		return err
+	}
+	err = u.createAnchor()
+	if err != nil {
+		return err
+	}
+	err = u.handleOrphans()
+	if err != nil {
+		return err
+	}
+	err = u.initDockerClient()
 	if err != nil {
 		return err
 	}
-	u.dockerClient = dc
 
 	for app := range u.appsToBeStarted {
 		// Begin pulling and pushing images immediately...
@@ -1043,7 +1167,16 @@ func (u *upRunner) run() error {
 	// nolint
 	go u.createServicesAndGetPodHostAliasesOnce()
 
-	err = u.runStartInitialPods()
+	err = u.recreatePodsIfNeeded()
+	if err != nil {
+		return err
+	}
+	return u.runCreatePods()
+}
+
+// runCreatePods creates the pods of the apps that are to be started in an order that respects depends_on, and waits until they are ready.
+func (u *upRunner) runCreatePods() error {
+	err := u.runStartInitialPods()
 	if err != nil {
 		return err
 	}
@@ -1059,7 +1192,11 @@ func (u *upRunner) run() error {
 	}
 	// Wait for completed channels
 	for _, completedChannel := range u.completedChannels {
-		<-completedChannel
+		select {
+		case <-completedChannel:
+		case <-u.opts.Context.Done():
+			return u.opts.Context.Err()
+		}
 	}
 	return nil
 }
@@ -1086,11 +1223,11 @@ func (u *upRunner) runWatchPodsEvent(event *k8swatch.Event) error {
 
 func (u *upRunner) runWatchPods(resourceVersion string) error {
 	if u.checkIfPodsReady() {
-		fmt.Printf("pods ready (%d/%d)\n", len(u.appsThatNeedToBeReady), len(u.appsThatNeedToBeReady))
+		u.cfg.EventHandler.Infof("", "pods ready (%d/%d)", len(u.appsThatNeedToBeReady), len(u.appsThatNeedToBeReady))
 		return nil
 	}
 	listOptions := metav1.ListOptions{
-		LabelSelector: u.cfg.EnvironmentLabel + "=" + u.cfg.EnvironmentID,
+		LabelSelector: k8smeta.GetEnvironmentSelector(u.cfg),
 	}
 	listOptions.ResourceVersion = resourceVersion
 	listOptions.Watch = true
@@ -1101,7 +1238,13 @@ func (u *upRunner) runWatchPods(resourceVersion string) error {
 	defer watch.Stop()
 	eventChannel := watch.ResultChan()
 	for {
-		event, ok := <-eventChannel
+		var event k8swatch.Event
+		var ok bool
+		select {
+		case event, ok = <-eventChannel:
+		case <-u.opts.Context.Done():
+			return u.opts.Context.Err()
+		}
 		if !ok {
 			return fmt.Errorf("channel unexpectedly closed")
 		}
@@ -1113,7 +1256,7 @@ func (u *upRunner) runWatchPods(resourceVersion string) error {
 			break
 		}
 	}
-	fmt.Printf("pods ready (%d/%d)\n", len(u.appsThatNeedToBeReady), len(u.appsThatNeedToBeReady))
+	u.cfg.EventHandler.Infof("", "pods ready (%d/%d)", len(u.appsThatNeedToBeReady), len(u.appsThatNeedToBeReady))
 	return nil
 }
 
@@ -1127,14 +1270,23 @@ func (u *upRunner) checkIfPodsReady() bool {
 	return allPodsReady
 }
 
-// Run runs an operation similar docker-compose up against a Kubernetes cluster.
-func Run(cfg *config.Config, opts *Options) error {
-	// TODO https://github.com/kube-compose/kube-compose/issues/2 accept context as a parameter
+func newUpRunner(cfg *config.Config, opts *Options) *upRunner {
+	if opts.Context == nil {
+		optsCopy := *opts
+		optsCopy.Context = context.Background()
+		opts = &optsCopy
+	}
 	u := &upRunner{
 		cfg:  cfg,
 		opts: opts,
 	}
 	u.hostAliases.once = &sync.Once{}
 	u.localImagesCache.once = &sync.Once{}
-	return u.run()
+	return u
+}
+
+// Run runs an operation similar docker-compose up against a Kubernetes cluster. Run returns the error of the context of the options when
+// it is done.
+func Run(cfg *config.Config, opts *Options) error {
+	return newUpRunner(cfg, opts).run()
 }
@@ -1,10 +1,18 @@
 package up
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
 	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
 	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 )
 
@@ -32,6 +40,42 @@ func newTestConfig() *config.Config {
 	return cfg
 }
 
+// newTestUpRunner returns an up runner for the configuration of newTestConfig in the environment myenv and namespace myns. Only the docker
+// compose services with the specified names are started, or all of them if names is empty. The up runner uses a fake Kubernetes client
+// that has the specified objects. opts can be nil.
+func newTestUpRunner(opts *Options, names []string, objects ...runtime.Object) *upRunner {
+	cfg := newTestConfig()
+	cfg.AppLabel = config.DefaultAppLabel
+	cfg.EnvironmentID = "myenv"
+	cfg.EnvironmentLabel = config.DefaultEnvironmentLabel
+	cfg.Namespace = "myns"
+	if len(names) == 0 {
+		for _, service := range cfg.Services {
+			cfg.AddToFilterWithoutDependencies(service)
+		}
+	}
+	for _, name := range names {
+		cfg.AddToFilterWithoutDependencies(cfg.FindServiceByName(name))
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	if opts.KubernetesClient == nil {
+		opts.KubernetesClient = fake.NewSimpleClientset(objects...)
+	}
+	u := &upRunner{
+		cfg:  cfg,
+		opts: opts,
+	}
+	_ = u.initKubernetesClientset()
+	u.initApps()
+	u.initAppsToBeStarted()
+	return u
+}
+
 func newTestApp(serviceName string) *app {
 	cfg := newTestConfig()
 	app := &app{
@@ -104,12 +148,30 @@ func TestUpRunnerInitKubernetesClientset(t *testing.T) {
 		KubeConfig: kubeConfig,
 	}
 	u := &upRunner{
-		cfg: cfg,
+		cfg:  cfg,
+		opts: &Options{},
+	}
+	err := u.initKubernetesClientset()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpRunnerInitKubernetesClientset_KubernetesClient(t *testing.T) {
+	k8sClient := &kubernetes.Clientset{}
+	u := &upRunner{
+		cfg: &config.Config{},
+		opts: &Options{
+			KubernetesClient: k8sClient,
+		},
 	}
 	err := u.initKubernetesClientset()
 	if err != nil {
 		t.Error(err)
 	}
+	if u.k8sClientset != k8sClient {
+		t.Fail()
+	}
 }
 
 func TestFormatCreatePodReason(t *testing.T) {
@@ -125,3 +187,81 @@ func TestFormatCreatePodReason(t *testing.T) {
 		t.Error(s)
 	}
 }
+
+func TestAppHasClusterIP_Headless(t *testing.T) {
+	app := newTestApp("a")
+	app.composeService.Headless = true
+	if !app.hasService() || app.hasClusterIP() {
+		t.Fail()
+	}
+}
+
+func TestAppGetArgsAndCommand_EmptyEntrypoint(t *testing.T) {
+	app := newTestApp("a")
+	app.composeService.DockerComposeService.Entrypoint = []string{}
+	app.composeService.DockerComposeService.Command = []string{"echo", "hello"}
+	c := &v1.Container{}
+	err := app.GetArgsAndCommand(c)
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(c.Command, []string{"echo", "hello"}) || len(c.Args) != 0 {
+		t.Error(c)
+	}
+}
+
+func TestAppGetArgsAndCommand_AbsentEntrypoint(t *testing.T) {
+	app := newTestApp("a")
+	app.composeService.DockerComposeService.Command = []string{"echo", "hello"}
+	c := &v1.Container{}
+	err := app.GetArgsAndCommand(c)
+	if err != nil {
+		t.Error(err)
+	} else if c.Command != nil || !reflect.DeepEqual(c.Args, []string{"echo", "hello"}) {
+		t.Error(c)
+	}
+}
+
+func TestInitServiceAppProtocols(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.Ports = []config.Port{
+		{Port: 80, Protocol: "tcp", Name: "web", AppProtocol: "http"},
+		{Port: 81, Protocol: "tcp"},
+		{Port: 443, Protocol: "tcp", AppProtocol: "https"},
+	}
+	service := &v1.Service{}
+	service.Annotations = map[string]string{}
+	service.Spec.Ports = []v1.ServicePort{{Name: "web"}, {Name: "tcp81"}, {Name: "tcp443"}}
+	initServiceAppProtocols(a, service)
+	if value := service.Annotations[k8smeta.AppProtocolsAnnotationName]; value != `{"tcp443":"https","web":"http"}` {
+		t.Error(value)
+	}
+}
+
+func TestInitServiceAppProtocols_None(t *testing.T) {
+	a := newTestApp("a")
+	a.composeService.Ports = []config.Port{
+		{Port: 80, Protocol: "tcp"},
+	}
+	service := &v1.Service{}
+	service.Annotations = map[string]string{}
+	service.Spec.Ports = []v1.ServicePort{{Name: "tcp80"}}
+	initServiceAppProtocols(a, service)
+	if len(service.Annotations) > 0 {
+		t.Error(service.Annotations)
+	}
+}
+
+func TestWaitForServiceClusterIPWatch_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	u := &upRunner{
+		cfg: newTestConfig(),
+		opts: &Options{
+			Context: ctx,
+		},
+	}
+	err := u.waitForServiceClusterIPWatch(1, 1, make(chan k8swatch.Event))
+	if err != context.Canceled {
+		t.Error(err)
+	}
+}
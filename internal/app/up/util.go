@@ -15,7 +15,6 @@ import (
 	dockerTypes "github.com/docker/docker/api/types"
 	dockerContainers "github.com/docker/docker/api/types/container"
 	dockerFilters "github.com/docker/docker/api/types/filters"
-	dockerClient "github.com/docker/docker/client"
 	dockerArchive "github.com/docker/docker/pkg/archive"
 	"github.com/kube-compose/kube-compose/internal/pkg/docker"
 	"github.com/kube-compose/kube-compose/internal/pkg/unix"
@@ -118,7 +117,7 @@ func inspectImageRawParseHealthcheck(inspectRaw []byte) (*dockerComposeConfig.He
 	return healthcheck, nil
 }
 
-func copyFileFromContainer(ctx context.Context, dc *dockerClient.Client, containerID, srcFile, dstFile string) error {
+func copyFileFromContainer(ctx context.Context, dc DockerClient, containerID, srcFile, dstFile string) error {
 	readCloser, stat, err := dc.CopyFromContainer(ctx, containerID, srcFile)
 	if err != nil {
 		return err
@@ -141,7 +140,7 @@ func copyFileFromContainer(ctx context.Context, dc *dockerClient.Client, contain
 	return nil
 }
 
-func getUserinfoFromImage(ctx context.Context, dc *dockerClient.Client, image string, user *docker.Userinfo) error {
+func getUserinfoFromImage(ctx context.Context, dc DockerClient, a *app, image string, user *docker.Userinfo) error {
 	containerConfig := &dockerContainers.Config{
 		Entrypoint: []string{"sh"},
 		Image:      image,
@@ -154,7 +153,7 @@ func getUserinfoFromImage(ctx context.Context, dc *dockerClient.Client, image st
 	defer func() {
 		err = dc.ContainerRemove(ctx, resp.ID, dockerTypes.ContainerRemoveOptions{})
 		if err != nil {
-			fmt.Println(err)
+			a.warnf("error while removing container %s: %v", resp.ID, err)
 		}
 	}()
 	tmpDir, err := ioutil.TempDir("", "kube-compose-")
@@ -164,7 +163,7 @@ func getUserinfoFromImage(ctx context.Context, dc *dockerClient.Client, image st
 	defer func() {
 		err = os.RemoveAll(tmpDir)
 		if err != nil {
-			fmt.Println(err)
+			a.warnf("error while removing directory %s: %v", tmpDir, err)
 		}
 	}()
 	err = getUserinfoFromImageUID(ctx, dc, resp.ID, tmpDir, user)
@@ -174,7 +173,7 @@ func getUserinfoFromImage(ctx context.Context, dc *dockerClient.Client, image st
 	return getUserinfoFromImageGID(ctx, dc, resp.ID, tmpDir, user)
 }
 
-func getUserinfoFromImageUID(ctx context.Context, dc *dockerClient.Client, containerID, tmpDir string, user *docker.Userinfo) error {
+func getUserinfoFromImageUID(ctx context.Context, dc DockerClient, containerID, tmpDir string, user *docker.Userinfo) error {
 	// TODO https://github.com/kube-compose/kube-compose/issues/70 this is not correct for non-Linux containers
 	if user.UID == nil {
 		err := copyFileFromContainer(ctx, dc, containerID, unix.EtcPasswd, tmpDir)
@@ -194,7 +193,7 @@ func getUserinfoFromImageUID(ctx context.Context, dc *dockerClient.Client, conta
 	return nil
 }
 
-func getUserinfoFromImageGID(ctx context.Context, dc *dockerClient.Client, containerID, tmpDir string, user *docker.Userinfo) error {
+func getUserinfoFromImageGID(ctx context.Context, dc DockerClient, containerID, tmpDir string, user *docker.Userinfo) error {
 	// TODO https://github.com/kube-compose/kube-compose/issues/70 this is not correct for non-Linux containers
 	if user.GID == nil && user.Group != "" {
 		err := copyFileFromContainer(ctx, dc, containerID, "/etc/group", tmpDir)
@@ -264,7 +263,7 @@ func resolveLocalImageIDTag(ref dockerRef.Reference, familiarName string, localI
 // resolveLocalImageAfterPull resolves an image based on a repository and digest by querying the docker daemon.
 // This is exactly the information we have available after pulling an image.
 // Returns the image ID, repo digest and optionally an error.
-func resolveLocalImageAfterPull(ctx context.Context, dc *dockerClient.Client, named dockerRef.Named, digest string) (
+func resolveLocalImageAfterPull(ctx context.Context, dc DockerClient, named dockerRef.Named, digest string) (
 	imageID, repoDigest string, err error) {
 	filters := dockerFilters.NewArgs()
 	familiarName := dockerRef.FamiliarName(named)
@@ -295,7 +294,7 @@ func getTag(ref dockerRef.Reference) string {
 	return refWithTag.Tag()
 }
 
-func pullImageWithLogging(ctx context.Context, puller docker.ImagePuller, appName, image string) (string, error) {
+func pullImageWithLogging(ctx context.Context, puller docker.ImagePuller, a *app, image string) (string, error) {
 	lastLogTime := time.Now().Add(-2 * time.Second)
 	digest, err := docker.PullImage(ctx, puller, image, "123", func(pull *docker.PullOrPush) {
 		t := time.Now()
@@ -303,17 +302,17 @@ func pullImageWithLogging(ctx context.Context, puller docker.ImagePuller, appNam
 		if elapsed >= 2*time.Second {
 			lastLogTime = t
 			progress := pull.Progress()
-			fmt.Printf("app %s: pulling image %s (%.1f%%)\n", appName, image, progress*100.0)
+			a.infof("pulling image %s (%.1f%%)", image, progress*100.0)
 		}
 	})
 	if err != nil {
 		return "", err
 	}
-	fmt.Printf("app %s: pulling image %s (%.1f%%)   @%s\n", appName, image, 100.0, digest)
+	a.infof("pulling image %s (%.1f%%)   @%s", image, 100.0, digest)
 	return digest, nil
 }
 
-func pushImageWithLogging(ctx context.Context, pusher docker.ImagePusher, appName, image, bearerToken, imageDescr string) (string, error) {
+func pushImageWithLogging(ctx context.Context, pusher docker.ImagePusher, a *app, image, bearerToken, imageDescr string) (string, error) {
 	lastLogTime := time.Now().Add(-2 * time.Second)
 	registryAuth := docker.EncodeRegistryAuth("unused", bearerToken)
 	digest, err := docker.PushImage(ctx, pusher, image, registryAuth, func(push *docker.PullOrPush) {
@@ -322,12 +321,12 @@ func pushImageWithLogging(ctx context.Context, pusher docker.ImagePusher, appNam
 		if elapsed >= 2*time.Second {
 			lastLogTime = t
 			progress := push.Progress()
-			fmt.Printf("app %s: pushing %s %s (%.1f%%)\n", appName, imageDescr, image, progress*100.0)
+			a.infof("pushing %s %s (%.1f%%)", imageDescr, image, progress*100.0)
 		}
 	})
 	if err != nil {
 		return "", err
 	}
-	fmt.Printf("app %s: pushing %s %s (%.1f%%) @%s\n", appName, imageDescr, image, 100.0, digest)
+	a.infof("pushing %s %s (%.1f%%) @%s", imageDescr, image, 100.0, digest)
 	return digest, err
 }
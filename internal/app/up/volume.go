@@ -12,7 +12,6 @@ import (
 	"strings"
 
 	dockerTypes "github.com/docker/docker/api/types"
-	dockerClient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/kube-compose/kube-compose/internal/pkg/docker"
 	"github.com/kube-compose/kube-compose/internal/pkg/fs"
@@ -261,7 +260,7 @@ type buildVolumeInitImageResult struct {
 
 func buildVolumeInitImage(
 	ctx context.Context,
-	dc *dockerClient.Client,
+	dc DockerClient,
 	bindVolumeHostPaths []string,
 	volumeInitBaseImage string) (*buildVolumeInitImageResult, error) {
 	buildContextBytes, err := buildVolumeInitImageGetBuildContext(bindVolumeHostPaths)
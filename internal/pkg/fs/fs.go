@@ -3,6 +3,7 @@ package fs
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,6 +24,7 @@ type VirtualFileSystem interface {
 	MkdirAll(name string, perm os.FileMode) error
 	Lstat(name string) (os.FileInfo, error)
 	Open(name string) (FileDescriptor, error)
+	ReadFile(name string) ([]byte, error)
 	Readlink(name string) (string, error)
 	Stat(name string) (os.FileInfo, error)
 }
@@ -54,6 +56,10 @@ func (fs *osFileSystem) Open(name string) (FileDescriptor, error) {
 	return os.Open(name)
 }
 
+func (fs *osFileSystem) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
 func (fs *osFileSystem) Readlink(name string) (string, error) {
 	return os.Readlink(name)
 }
@@ -0,0 +1,15 @@
+package fs
+
+import (
+	"io/ioutil"
+)
+
+// ReadFile should behave the same as ioutil.ReadFile but operates on the virtual file system.
+func (fs *InMemoryFileSystem) ReadFile(name string) ([]byte, error) {
+	fd, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	return ioutil.ReadAll(fd)
+}
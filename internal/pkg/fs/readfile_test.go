@@ -0,0 +1,42 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func Test_ReadFile_Success(t *testing.T) {
+	fs := NewInMemoryUnixFileSystem(map[string]InMemoryFile{
+		"/success": {
+			Content: []byte("contents"),
+		},
+	})
+	contents, err := fs.ReadFile("/success")
+	if err != nil {
+		t.Error(err)
+	} else if string(contents) != "contents" {
+		t.Fail()
+	}
+}
+
+func Test_ReadFile_ErrorNotExist(t *testing.T) {
+	fs := NewInMemoryUnixFileSystem(map[string]InMemoryFile{})
+	_, err := fs.ReadFile("/errornotexist")
+	if !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
+
+func Test_ReadFile_ErrorInjectedFault(t *testing.T) {
+	errExpected := fmt.Errorf("errorInjectedFault")
+	fs := NewInMemoryUnixFileSystem(map[string]InMemoryFile{
+		"/errorinjectedfault": {
+			ReadError: errExpected,
+		},
+	})
+	_, errActual := fs.ReadFile("/errorinjectedfault")
+	if errActual != errExpected {
+		t.Fail()
+	}
+}
@@ -1,14 +1,20 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/kube-compose/kube-compose/internal/pkg/fs"
-	"github.com/kube-compose/kube-compose/internal/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/uber-go/mapdecode"
 	yaml "gopkg.in/yaml.v2"
@@ -21,6 +27,9 @@ var (
 	v3_3 = version.Must(version.NewVersion("3.3"))
 )
 
+// serviceNameRegexp matches the valid names of docker compose services, as per the schemas of the docker compose file format.
+var serviceNameRegexp = regexp.MustCompile("^[a-zA-Z0-9._-]+$")
+
 // TODO https://github.com/kube-compose/kube-compose/issues/11 ensure that the YAML decoder actually produces this
 // type for any YAML where the root is a mapping in the absence of type information.
 type genericMap map[interface{}]interface{}
@@ -32,6 +41,10 @@ type XProperties map[string]interface{}
 // It represents one ore more docker compose files that have been merged together using logic close to docker compose.
 // Similarly, extends will have been processed as well (see https://docs.docker.com/compose/compose-file/compose-file-v2/#extends).
 type CanonicalDockerComposeConfig struct {
+	// IgnoredKeys are the keys of the loaded docker compose files that were ignored, sorted by file, service and key.
+	IgnoredKeys []IgnoredKey
+	// Name is the project name set by the top-level name key of the docker compose file, and is empty if the key is absent.
+	Name        string
 	Services    map[string]*Service
 	XProperties XProperties
 }
@@ -39,23 +52,50 @@ type CanonicalDockerComposeConfig struct {
 // Service is the final representation of a docker-compose service, after all docker compose files have been merged. Service
 // is a smaller piece of CanonicalDockerComposeConfig.
 type Service struct {
+	CapAdd     []string
+	CapDrop    []string
 	Command    []string
 	DependsOn  map[*Service]ServiceHealthiness
-	Entrypoint []string
-
-	// docker-compose distinguishes between an empty Entrypoint and an absent Entrypoint.
-	// TODO https://github.com/kube-compose/kube-compose/issues/157 remove this field
-	EntrypointPresent   bool
-	Environment         map[string]string
+	DNS        []string
+	DNSSearch  []string
+	DomainName string
+	// docker-compose distinguishes between an empty Entrypoint and an absent Entrypoint. Entrypoint is nil if and only if it is absent.
+	Entrypoint  []string
+	Environment map[string]string
+	// Expose are the ports that are exposed to other docker compose services, but are not published. Each element has an ExternalMin
+	// of -1.
+	Expose     []PortBinding
+	ExtraHosts []ExtraHost
+	// GroupAdd are the additional groups of the user of a docker compose service. Each element is either a group name or a group ID.
+	GroupAdd            []string
 	Healthcheck         *Healthcheck
 	HealthcheckDisabled bool
+	Hostname            string
 	Image               string
-	Ports               []PortBinding
-	Privileged          bool
-	User                *string
-	Volumes             []ServiceVolume
-	WorkingDir          string
-	Restart             string
+	// Init is true if and only if an init process should run as PID 1 that forwards signals and reaps processes.
+	Init       bool
+	Labels     map[string]string
+	Ports      []PortBinding
+	Privileged bool
+	// Profiles are the profiles that enable the docker compose service. A service without profiles is always enabled.
+	Profiles []string
+	ReadOnly bool
+	// SecurityOpt are the unparsed security options of a docker compose service, for example "no-new-privileges" or "seccomp:unconfined".
+	SecurityOpt []string
+	// ShmSize is the size of /dev/shm in bytes, and is nil if and only if the docker compose service did not set shm_size.
+	ShmSize *int64
+	// StopGracePeriod is nil if and only if the docker compose service did not set stop_grace_period.
+	StopGracePeriod *time.Duration
+	StopSignal      string
+	Sysctls         map[string]string
+	Ulimits         map[string]Ulimit
+	User            *string
+	UsernsMode      string
+	Volumes         []ServiceVolume
+	WorkingDir      string
+	Restart         string
+	// XProperties are the extension fields of the docker compose service (keys starting with x-).
+	XProperties XProperties
 }
 
 // composeFileParsedService is a helper struct that is a smaller piece of composeFileParsed.
@@ -77,8 +117,15 @@ func (c *composeFileParsedService) clearRecStack() {
 // composeFileParsed is an intermediate representation of a docker compose file used during loading
 // of the docker compose configuration.
 type composeFileParsed struct {
+	// dir is the directory that relative paths of the docker compose file are relative to.
+	dir         string
+	ignoredKeys []IgnoredKey
+	// loading is true while the file is being loaded, and is used to detect cyclical includes.
+	loading  bool
+	name     string
 	services map[string]*composeFileParsedService
-	version  *version.Version
+	// version is the version of the docker compose file format, and is nil if the file follows the Compose Specification.
+	version *version.Version
 	// Extension fields at the root of the compose file represented by this struct.
 	xProperties XProperties
 	// The resolved file that contains the docker compose file represented by this struct.
@@ -93,8 +140,25 @@ type loadResolvedFileCacheItem struct {
 	err    error
 }
 
+// StdinFile is the file that denotes standard input, as in docker compose -f -.
+const StdinFile = "-"
+
+// Options configure how docker compose files are loaded.
+type Options struct {
+	// FileSystem is the file system that docker compose files are read from. If nil, fs.OS is used.
+	FileSystem fs.VirtualFileSystem
+	// ProjectDirectory is the directory that relative paths of the specified files are relative to. If empty, the directory of each file is
+	// used, and the current working directory is used for standard input. Files that are loaded because of extends or include always use
+	// their own directory.
+	ProjectDirectory string
+	// Stdin is read when StdinFile is specified. If nil, os.Stdin is used.
+	Stdin io.Reader
+}
+
 type configLoader struct {
 	environmentGetter ValueGetter
+	fileSystem        fs.VirtualFileSystem
+	stdin             io.Reader
 	// A cache required to detect cycles when processing extends. Additionally, each file is only
 	// processed once so that loading of configuration is faster.
 	loadResolvedFileCache map[string]*loadResolvedFileCacheItem
@@ -105,21 +169,38 @@ func loadFileError(file string, err error) error {
 }
 
 // loadFile loads the specified file. If the file has already been loaded then a cache lookup is performed.
-// If file is relative then it is interpreted relative to the current working directory.
-func (c *configLoader) loadFile(file string) (*composeFileParsed, error) {
-	resolvedFile, err := fs.OS.EvalSymlinks(file)
-	if err != nil {
-		return nil, loadFileError(file, err)
+// If file is relative then it is interpreted relative to the current working directory. Relative paths of the file are relative to dir,
+// or to the directory of the file if dir is empty.
+func (c *configLoader) loadFile(file, dir string) (*composeFileParsed, error) {
+	resolvedFile := file
+	if file != StdinFile {
+		var err error
+		resolvedFile, err = c.fileSystem.EvalSymlinks(file)
+		if err != nil {
+			return nil, loadFileError(file, err)
+		}
+		if dir == "" {
+			dir = filepath.Dir(resolvedFile)
+		}
+	} else if dir == "" {
+		dir = "."
 	}
-	return c.loadResolvedFile(resolvedFile)
+	return c.loadResolvedFile(resolvedFile, dir)
 }
 
-// getVersion is a utility used to retrieve the version from a docker compose file after it has been mapdecode'd.
+// composeSpecificationKeys are the top-level keys that mark a file without a version as a file that follows the Compose Specification.
+var composeSpecificationKeys = []string{"configs", "include", "name", "networks", "secrets", "services", "volumes"}
+
+// getVersion is a utility used to retrieve the version from a docker compose file after it has been mapdecode'd. Files without a
+// version follow the Compose Specification if they have a top-level key of composeSpecificationKeys, for which nil is returned, and are
+// version 1 otherwise.
 func getVersion(dataMap genericMap) (*version.Version, error) {
 	var v *version.Version
 	vRaw, hasVersion := dataMap["version"]
 	if !hasVersion {
-		v = v1
+		if !hasComposeSpecificationKey(dataMap) {
+			v = v1
+		}
 	} else if vString, ok := vRaw.(string); ok {
 		var err error
 		v, err = version.NewVersion(vString)
@@ -132,33 +213,53 @@ func getVersion(dataMap genericMap) (*version.Version, error) {
 	return v, nil
 }
 
+func hasComposeSpecificationKey(dataMap genericMap) bool {
+	for _, key := range composeSpecificationKeys {
+		if _, ok := dataMap[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // loadResolvedFile is a wrapper around loadResolvedFileCore that loads and populates a cache.
-func (c *configLoader) loadResolvedFile(resolvedFile string) (*composeFileParsed, error) {
+func (c *configLoader) loadResolvedFile(resolvedFile, dir string) (*composeFileParsed, error) {
 	cacheItem := c.loadResolvedFileCache[resolvedFile]
 	if cacheItem == nil {
 		// Add an item to the cache before loadResolvedFileCore so that a recursive call within loadResolvedFileCore
 		// can detect cycles.
 		cacheItem = &loadResolvedFileCacheItem{
-			parsed: &composeFileParsed{},
+			parsed: &composeFileParsed{
+				dir: dir,
+			},
 		}
 		c.loadResolvedFileCache[resolvedFile] = cacheItem
+		cacheItem.parsed.loading = true
 		cacheItem.err = c.loadResolvedFileCore(resolvedFile, cacheItem.parsed)
-
+		cacheItem.parsed.loading = false
 	}
 	return cacheItem.parsed, cacheItem.err
 }
 
-// loadYamlFileAsGenericMap is a helper used to YAML decode a file into a map[interface{}]interface{}.
-func loadYamlFileAsGenericMap(file string) (genericMap, error) {
-	reader, err := fs.OS.Open(file)
+// readFile reads a docker compose file from the file system, or from standard input if file is StdinFile.
+func (c *configLoader) readFile(file string) ([]byte, error) {
+	if file == StdinFile {
+		return ioutil.ReadAll(c.stdin)
+	}
+	return c.fileSystem.ReadFile(file)
+}
+
+// loadYamlFileAsGenericMap is a helper used to YAML decode a file into a map[interface{}]interface{}. The contents of the file are also
+// returned, so that the positions of YAML nodes can be looked up.
+func (c *configLoader) loadYamlFileAsGenericMap(file string) (genericMap, []byte, error) {
+	contents, err := c.readFile(file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer util.CloseAndLogError(reader)
-	decoder := yaml.NewDecoder(reader)
+	decoder := yaml.NewDecoder(bytes.NewReader(moveMergeKeysFirst(contents)))
 	var dataMap genericMap
 	err = decoder.Decode(&dataMap)
-	return dataMap, err
+	return dataMap, contents, err
 }
 
 // loadResolvedFileCore loads a docker compose file, and does any validation/canonicalization that does not require
@@ -168,9 +269,10 @@ func (c *configLoader) loadResolvedFileCore(resolvedFile string, cfParsed *compo
 
 	// Load YAML file as map[interface{}]interface{}. This type is used so that we can subsequently
 	// interpolate environment variables and extract x- properties.
-	dataMap, err := loadYamlFileAsGenericMap(resolvedFile)
+	dataMap, contents, err := c.loadYamlFileAsGenericMap(resolvedFile)
 	if err != nil {
-		return err
+		// YAML syntax errors have a line but not a file.
+		return loadFileError(resolvedFile, err)
 	}
 
 	// extract docker compose file version
@@ -182,16 +284,20 @@ func (c *configLoader) loadResolvedFileCore(resolvedFile string, cfParsed *compo
 	// Substitute variables with environment variables.
 	err = InterpolateConfig(dataMap, c.environmentGetter, cfParsed.version)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "error interpolating file %#v", resolvedFile)
 	}
 
-	if !cfParsed.version.Equal(v1) {
+	if !isV1(cfParsed.version) {
 		// extract x- properties
 		cfParsed.xProperties = getXProperties(dataMap)
 	} else {
-		dataMap = map[interface{}]interface{}{
-			"services": dataMap,
-		}
+		dataMap = liftV1Services(dataMap)
+	}
+
+	// Validate against the JSON schema first, so that all violations are reported with their positions.
+	err = validateSchema(resolvedFile, contents, dataMap, cfParsed.version)
+	if err != nil {
+		return err
 	}
 
 	// mapdecode based on docker compose file schema
@@ -200,23 +306,80 @@ func (c *configLoader) loadResolvedFileCore(resolvedFile string, cfParsed *compo
 	if err != nil {
 		return err
 	}
+	setComposeFileServiceXProperties(&cf, dataMap)
+	cfParsed.ignoredKeys = getIgnoredKeys(resolvedFile, dataMap, cfParsed.version)
 
 	// validation after parsing
-	return c.parseComposeFile(&cf, cfParsed)
+	err = c.parseComposeFile(&cf, cfParsed)
+	if err != nil {
+		return err
+	}
+	cfParsed.name = cf.Name
+	return c.processIncludes(cf.Include, cfParsed)
+}
+
+// isV1 returns true if and only if v is version 1 of the docker compose file format. v is nil for the Compose Specification.
+func isV1(v *version.Version) bool {
+	return v != nil && v.Equal(v1)
+}
+
+// liftV1Services adapts a version 1 docker compose file, which has its services at the root, to the structure of the other versions.
+func liftV1Services(dataMap genericMap) genericMap {
+	return genericMap{
+		"services": dataMap,
+	}
+}
+
+// processIncludes adds the services of the files included by a docker compose file (see
+// https://github.com/compose-spec/compose-spec/blob/master/14-include.md). Included files are loaded as standalone files, so extends
+// of included services are processed relative to the included file. A service name may only be defined once.
+func (c *configLoader) processIncludes(includes []include, cfParsed *composeFileParsed) error {
+	for _, include := range includes {
+		if len(include.EnvFile) > 0 || include.ProjectDirectory != nil {
+			return fmt.Errorf("file %#v has an include with env_file or project_directory, which is not supported", cfParsed.resolvedFile)
+		}
+		if len(include.Path) != 1 {
+			return fmt.Errorf("file %#v has an include with %d paths, but exactly one path is supported", cfParsed.resolvedFile,
+				len(include.Path))
+		}
+		cfParsedIncluded, err := c.loadFile(expandPath(cfParsed.dir, include.Path[0]), "")
+		if err != nil {
+			return err
+		}
+		if cfParsedIncluded.loading {
+			return fmt.Errorf("file %#v includes %#v, which would cause an infinite loop", cfParsed.resolvedFile,
+				cfParsedIncluded.resolvedFile)
+		}
+		for name, cfServiceParsed := range cfParsedIncluded.services {
+			err = c.processExtends(name, cfServiceParsed, cfParsedIncluded)
+			if err != nil {
+				return err
+			}
+			if cfParsed.services[name] != nil {
+				return fmt.Errorf("service %s of file %#v conflicts with a service of the included file %#v", name,
+					cfParsed.resolvedFile, cfParsedIncluded.resolvedFile)
+			}
+			cfParsed.services[name] = cfServiceParsed
+		}
+	}
+	return nil
 }
 
 // loadStandardFile loads the docker compose file at a standard location.
-func (c *configLoader) loadStandardFile() (*composeFileParsed, error) {
+func (c *configLoader) loadStandardFile(dir string) (*composeFileParsed, error) {
 	file := "docker-compose.yml"
-	resolvedFile, err := fs.OS.EvalSymlinks(file)
+	resolvedFile, err := c.fileSystem.EvalSymlinks(file)
 	if os.IsNotExist(err) {
 		file = "docker-compose.yaml"
-		resolvedFile, err = fs.OS.EvalSymlinks(file)
+		resolvedFile, err = c.fileSystem.EvalSymlinks(file)
+	}
+	if err != nil {
+		return nil, loadFileError(file, err)
 	}
-	if err == nil {
-		return c.loadResolvedFile(resolvedFile)
+	if dir == "" {
+		dir = filepath.Dir(resolvedFile)
 	}
-	return nil, loadFileError(file, err)
+	return c.loadResolvedFile(resolvedFile, dir)
 }
 
 // processExtends process the extends field of a docker compose service. That is: given a docker compose service X named name in the docker
@@ -241,7 +404,8 @@ func (c *configLoader) processExtends(name string, cfServiceParsed *composeFileP
 		return err
 	}
 	merge(cfServiceParsed, cfExtendedServiceParsed)
-	return nil
+	// Merging can exceed the maximum number of nameservers.
+	return validateDNS(name, cfServiceParsed.service)
 }
 
 // resolveExtends ensures the configuration of an extended docker compose service has been loaded.
@@ -257,9 +421,9 @@ func (c *configLoader) resolveExtends(
 	var cfExtendedServiceParsed *composeFileParsedService
 	var cfParsedExtends *composeFileParsed
 	if cfServiceParsed.extends.File != nil {
-		extendsFile := expandPath(cfParsed.resolvedFile, *cfServiceParsed.extends.File)
+		extendsFile := expandPath(cfParsed.dir, *cfServiceParsed.extends.File)
 		var err error
-		cfParsedExtends, err = c.loadFile(extendsFile)
+		cfParsedExtends, err = c.loadFile(extendsFile, "")
 		if err != nil {
 			return nil, err
 		}
@@ -298,21 +462,38 @@ func (c *configLoader) resolveExtends(
 // New loads docker compose configuration from a slice of files.
 // If files is an empty slice then the standard docker compose file locations (relative to the current working directory are considered).
 func New(files []string) (*CanonicalDockerComposeConfig, error) {
+	return NewWithOptions(files, nil)
+}
+
+// NewWithOptions is like New, but allows the file system, the standard input and the project directory to be configured. A file can be
+// StdinFile to read a docker compose file from standard input. opts can be nil.
+func NewWithOptions(files []string, opts *Options) (*CanonicalDockerComposeConfig, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
 	c := &configLoader{
 		environmentGetter:     os.LookupEnv,
+		fileSystem:            opts.FileSystem,
 		loadResolvedFileCache: map[string]*loadResolvedFileCacheItem{},
+		stdin:                 opts.Stdin,
+	}
+	if c.fileSystem == nil {
+		c.fileSystem = fs.OS
+	}
+	if c.stdin == nil {
+		c.stdin = os.Stdin
 	}
 	var resolvedFiles []string
 	if len(files) > 0 {
 		for _, file := range files {
-			cfParsed, err := c.loadFile(file)
+			cfParsed, err := c.loadFile(file, opts.ProjectDirectory)
 			if err != nil {
 				return nil, err
 			}
 			resolvedFiles = append(resolvedFiles, cfParsed.resolvedFile)
 		}
 	} else {
-		cfParsed, err := c.loadStandardFile()
+		cfParsed, err := c.loadStandardFile(opts.ProjectDirectory)
 		if err != nil {
 			return nil, err
 		}
@@ -344,10 +525,26 @@ func New(files []string) (*CanonicalDockerComposeConfig, error) {
 	for name, cfServiceParsed := range cfParsed.services {
 		configCanonical.Services[name] = cfServiceParsed.service
 	}
+	configCanonical.Name = cfParsed.name
 	configCanonical.XProperties = cfParsed.xProperties
+	configCanonical.IgnoredKeys = c.getIgnoredKeys()
 	return configCanonical, nil
 }
 
+// getIgnoredKeys returns the ignored keys of all loaded files, including files that were loaded because of extends.
+func (c *configLoader) getIgnoredKeys() []IgnoredKey {
+	resolvedFiles := make([]string, 0, len(c.loadResolvedFileCache))
+	for resolvedFile := range c.loadResolvedFileCache {
+		resolvedFiles = append(resolvedFiles, resolvedFile)
+	}
+	sort.Strings(resolvedFiles)
+	var ignoredKeys []IgnoredKey
+	for _, resolvedFile := range resolvedFiles {
+		ignoredKeys = append(ignoredKeys, c.loadResolvedFileCache[resolvedFile].parsed.ignoredKeys...)
+	}
+	return ignoredKeys
+}
+
 // getXProperties is a utility that gets all string properties starting with x- from gm, if gm is of type map[interface{}]interface{}.
 func getXProperties(gm interface{}) XProperties {
 	gmMap, ok := gm.(genericMap)
@@ -367,6 +564,19 @@ func getXProperties(gm interface{}) XProperties {
 	return result
 }
 
+// setComposeFileServiceXProperties extracts the x- properties of each docker compose service, since these are ignored by mapdecode.
+func setComposeFileServiceXProperties(cf *composeFile, dataMap genericMap) {
+	servicesMap, ok := dataMap["services"].(genericMap)
+	if !ok {
+		return
+	}
+	for name, cfService := range cf.Services {
+		if cfService != nil {
+			cfService.xProperties = getXProperties(servicesMap[name])
+		}
+	}
+}
+
 func resolveDependsOn(cfParsed *composeFileParsed) error {
 	for name1, cfServiceParsed := range cfParsed.services {
 		service := cfServiceParsed.service
@@ -418,7 +628,15 @@ func ensureNoDependsOnCycle(name1 string, cfServiceParsed *composeFileParsedServ
 func (c *configLoader) parseComposeFile(cf *composeFile, cfParsed *composeFileParsed) error {
 	cfParsed.services = make(map[string]*composeFileParsedService, len(cf.Services))
 	for name, cfService := range cf.Services {
-		composeFileParsedService, err := c.parseComposeFileService(cfParsed.resolvedFile, cfService)
+		if !serviceNameRegexp.MatchString(name) {
+			return fmt.Errorf("file %#v has a service with invalid name %#v, service names may only contain letters, digits, \".\", \"_\" "+
+				"and \"-\"", cfParsed.resolvedFile, name)
+		}
+		composeFileParsedService, err := c.parseComposeFileService(cfParsed.dir, cfService)
+		if err != nil {
+			return err
+		}
+		err = validateDNS(name, composeFileParsedService.service)
 		if err != nil {
 			return err
 		}
@@ -427,15 +645,31 @@ func (c *configLoader) parseComposeFile(cf *composeFile, cfParsed *composeFilePa
 	return nil
 }
 
-func (c *configLoader) parseComposeFileService(resolvedFile string, cfService *composeFileService) (*composeFileParsedService, error) {
+func (c *configLoader) parseComposeFileService(dir string, cfService *composeFileService) (*composeFileParsedService, error) {
 	service := &Service{
-		Command:    cfService.Command.Values,
-		Image:      cfService.Image,
-		Privileged: cfService.Privileged,
-		User:       cfService.User,
-		Volumes:    cfService.Volumes,
-		WorkingDir: cfService.WorkingDir,
-		Restart:    cfService.Restart,
+		CapAdd:      cfService.CapAdd,
+		CapDrop:     cfService.CapDrop,
+		Command:     cfService.Command.Values,
+		DNS:         cfService.DNS.Values,
+		DNSSearch:   cfService.DNSSearch.Values,
+		DomainName:  cfService.DomainName,
+		Hostname:    cfService.Hostname,
+		Image:       cfService.Image,
+		Init:        cfService.Init,
+		Labels:      cfService.Labels.Values,
+		GroupAdd:    cfService.GroupAdd.Values,
+		Privileged:  cfService.Privileged,
+		Profiles:    cfService.Profiles,
+		ReadOnly:    cfService.ReadOnly,
+		SecurityOpt: cfService.SecurityOpt,
+		StopSignal:  cfService.StopSignal,
+		Sysctls:     cfService.Sysctls.Values,
+		User:        cfService.User,
+		UsernsMode:  cfService.UsernsMode,
+		Volumes:     cfService.Volumes,
+		WorkingDir:  cfService.WorkingDir,
+		Restart:     cfService.Restart,
+		XProperties: cfService.xProperties,
 	}
 	composeFileParsedService := &composeFileParsedService{
 		service: service,
@@ -443,17 +677,55 @@ func (c *configLoader) parseComposeFileService(resolvedFile string, cfService *c
 	}
 	if cfService.Entrypoint != nil {
 		service.Entrypoint = cfService.Entrypoint.Values
-		service.EntrypointPresent = true
+		if service.Entrypoint == nil {
+			service.Entrypoint = []string{}
+		}
 	}
 	if cfService.DependsOn != nil {
 		composeFileParsedService.dependsOn = cfService.DependsOn.Values
 	}
+	if cfService.ShmSize != nil {
+		service.ShmSize = new(int64)
+		*service.ShmSize = cfService.ShmSize.Value
+	}
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	if service.Sysctls == nil {
+		service.Sysctls = map[string]string{}
+	}
+	service.Ulimits = make(map[string]Ulimit, len(cfService.Ulimits))
+	for name, ulimit := range cfService.Ulimits {
+		if ulimit == nil {
+			return nil, fmt.Errorf("ulimits entry %s must have a value", name)
+		}
+		service.Ulimits[name] = ulimit.Value
+	}
 	ports, err := parsePorts(cfService.Ports)
 	if err != nil {
 		return nil, err
 	}
 	service.Ports = ports
 
+	expose, err := parseExpose(cfService.Expose)
+	if err != nil {
+		return nil, err
+	}
+	service.Expose = expose
+
+	if cfService.StopGracePeriod != nil {
+		service.StopGracePeriod, err = parseStopGracePeriod(*cfService.StopGracePeriod)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	extraHosts, err := parseExtraHosts(cfService.ExtraHosts.Values)
+	if err != nil {
+		return nil, err
+	}
+	service.ExtraHosts = extraHosts
+
 	healthcheck, healthcheckDisabled, err := ParseHealthcheck(cfService.Healthcheck)
 	if err != nil {
 		return nil, err
@@ -469,12 +741,26 @@ func (c *configLoader) parseComposeFileService(resolvedFile string, cfService *c
 
 	// TODO https://github.com/kube-compose/kube-compose/issues/163 only resolve volume paths if volume_driver is not set.
 	for i := 0; i < len(service.Volumes); i++ {
-		resolveBindMountVolumeHostPath(resolvedFile, &service.Volumes[i])
+		resolveBindMountVolumeHostPath(dir, &service.Volumes[i])
 	}
 
 	return composeFileParsedService, nil
 }
 
+// parseStopGracePeriod parses the stop_grace_period of a docker compose service. Like the durations of healthchecks, time.ParseDuration
+// supports a superset of the durations of docker compose:
+// https://docs.docker.com/compose/compose-file/compose-file-v2/#specifying-durations
+func parseStopGracePeriod(value string) (*time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("field \"stop_grace_period\" must not be negative")
+	}
+	return &d, nil
+}
+
 func (c *configLoader) parseEnvironment(env []environmentNameValuePair) (map[string]string, error) {
 	envParsed := make(map[string]string, len(env))
 	for _, pair := range env {
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/kube-compose/kube-compose/internal/pkg/fs"
 	"github.com/kube-compose/kube-compose/internal/pkg/util"
@@ -24,6 +27,15 @@ const testDockerComposeYmlExtendsInvalidDependsOn = "/docker-compose.extends-inv
 const testDockerComposeYmlDependsOnDoesNotExist = "/docker-compose.depends-on-does-not-exist.yml"
 const testDockerComposeYmlDependsOnCycle = "/docker-compose.depends-on-cycle.yml"
 const testDockerComposeYmlDependsOn = "/docker-compose.depends-on.yml"
+const testDockerComposeYmlServiceXProperties = "/docker-compose.service-x-properties.yml"
+const testDockerComposeYmlIgnoredKeys = "/docker-compose.ignored-keys.yml"
+const testDockerComposeYmlComposeSpec = "/docker-compose.compose-spec.yml"
+const testDockerComposeYmlMergeKeys = "/docker-compose.merge-keys.yml"
+const testDockerComposeYmlIncluded = "/included/docker-compose.yml"
+const testDockerComposeYmlIncludeConflict = "/docker-compose.include-conflict.yml"
+const testDockerComposeYmlIncludeCycle = "/docker-compose.include-cycle.yml"
+const testDockerComposeYmlIncludeOnly = "/docker-compose.include-only.yml"
+const testDockerComposeYmlIncludeProjectDirectory = "/docker-compose.include-project-directory.yml"
 
 var mockFS = fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
 	testDockerComposeYml: {
@@ -73,6 +85,110 @@ services:
     extends:
       file: '` + testDockerComposeYml[1:] + `'
       service: testservice
+`),
+	},
+	testDockerComposeYmlServiceXProperties: {
+		Content: []byte(`version: '2.3'
+services:
+  service1:
+    extends:
+      service: service2
+    labels:
+      label1: value1
+    x-a: 1
+  service2:
+    labels:
+      - label1=value2
+      - label2=value2
+    x-a: 2
+    x-b: 2
+`),
+	},
+	testDockerComposeYmlIgnoredKeys: {
+		Content: []byte(`version: '2.4'
+services:
+  service1:
+    image: ubuntu:latest
+    build: {context: .}
+    imagee: ubuntu:latest
+    tmpfs: /tmp
+    x-foo: bar
+networks: {}
+x-bar: baz
+`),
+	},
+	testDockerComposeYmlComposeSpec: {
+		Content: []byte(`name: myproject
+include:
+- included/docker-compose.yml
+services:
+  service1:
+    image: ubuntu:${TAG:-latest}
+    depends_on:
+    - service2
+    profiles: [debug]
+x-foo: ${FOO}
+`),
+	},
+	testDockerComposeYmlMergeKeys: {
+		Content: []byte(`version: '2.4'
+x-common: &common
+  image: ubuntu:latest
+  environment:
+    KEY1: VALUE1
+  x-kube-compose:
+    foo: bar
+x-user: &user
+  user: root
+  image: nginx:latest
+services:
+  service1:
+    image: alpine:latest
+    <<: *common
+  service2:
+    <<: [*common, *user]
+    x-a: 1
+  service3:
+    <<: *common
+    environment:
+      KEY2: VALUE2
+`),
+	},
+	testDockerComposeYmlIncluded: {
+		Content: []byte(`services:
+  service2:
+    extends:
+      service: service3
+  service3:
+    environment:
+      KEY: VALUE
+`),
+	},
+	testDockerComposeYmlIncludeConflict: {
+		Content: []byte(`include:
+- path: included/docker-compose.yml
+services:
+  service2:
+    image: ubuntu:latest
+`),
+	},
+	testDockerComposeYmlIncludeCycle: {
+		Content: []byte(`include:
+- ` + testDockerComposeYmlIncludeCycle[1:] + `
+services: {}
+`),
+	},
+	testDockerComposeYmlIncludeOnly: {
+		Content: []byte(`name: myproject
+include:
+- included/docker-compose.yml
+`),
+	},
+	testDockerComposeYmlIncludeProjectDirectory: {
+		Content: []byte(`include:
+- path: included/docker-compose.yml
+  project_directory: included
+services: {}
 `),
 	},
 	testDockerComposeYmlExtendsCycle: {
@@ -180,6 +296,7 @@ func withMockFS(cb func()) {
 func newTestConfigLoader(env map[string]string) *configLoader {
 	c := &configLoader{
 		environmentGetter:     mapValueGetter(env),
+		fileSystem:            fs.OS,
 		loadResolvedFileCache: map[string]*loadResolvedFileCacheItem{},
 	}
 	return c
@@ -253,7 +370,7 @@ func TestConfigLoaderParseEnvironment_InvalidName(t *testing.T) {
 func TestConfigLoaderLoadFile_Success(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		cfParsed, err := c.loadFile(testDockerComposeYml)
+		cfParsed, err := c.loadFile(testDockerComposeYml, "")
 		if err != nil {
 			t.Error(err)
 		} else {
@@ -267,9 +384,9 @@ func TestConfigLoaderLoadFile_Success(t *testing.T) {
 				"testservice": {
 					dependsOn: map[string]ServiceHealthiness{},
 					service: &Service{
-						Command:           []string{"bash", "-c", "echo 'Hello World!'"},
-						EntrypointPresent: true,
-						Image:             "ubuntu:latest",
+						Command:    []string{"bash", "-c", "echo 'Hello World!'"},
+						Entrypoint: []string{},
+						Image:      "ubuntu:latest",
 						Volumes: []ServiceVolume{
 							{
 								Short: &PathMapping{
@@ -413,9 +530,9 @@ func assertServicesEqualContinued(t *testing.T, service1, service2 *Service, ign
 		t.Logf("env2: %+v\n", service2.Environment)
 		t.Fail()
 	}
-	if service1.EntrypointPresent != service2.EntrypointPresent {
+	if (service1.Entrypoint == nil) != (service2.Entrypoint == nil) {
 		t.Fail()
-	} else if service1.EntrypointPresent && !areStringSlicesEqual(service1.Entrypoint, service2.Entrypoint) {
+	} else if !areStringSlicesEqual(service1.Entrypoint, service2.Entrypoint) {
 		t.Logf("entrypoint1: %+v\n", service1.Entrypoint)
 		t.Logf("entrypoint2: %+v\n", service2.Entrypoint)
 		t.Fail()
@@ -484,7 +601,7 @@ func areStringSlicesEqual(slice1, slice2 []string) bool {
 func TestConfigLoaderLoadFile_Error(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		_, err := c.loadFile(testDockerComposeYmlIOError)
+		_, err := c.loadFile(testDockerComposeYmlIOError, "")
 		if err == nil {
 			t.Fail()
 		}
@@ -494,11 +611,11 @@ func TestConfigLoaderLoadFile_Error(t *testing.T) {
 func TestConfigLoaderLoadResolvedFile_Caching(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		cfParsed1, err := c.loadResolvedFile(testDockerComposeYml)
+		cfParsed1, err := c.loadResolvedFile(testDockerComposeYml, "/")
 		if err != nil {
 			t.Error(err)
 		}
-		cfParsed2, err := c.loadResolvedFile(testDockerComposeYml)
+		cfParsed2, err := c.loadResolvedFile(testDockerComposeYml, "/")
 		if err != nil {
 			t.Error(err)
 		}
@@ -511,7 +628,7 @@ func TestConfigLoaderLoadResolvedFile_Caching(t *testing.T) {
 func TestConfigLoaderLoadResolvedFile_OpenFileError(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		_, err := c.loadResolvedFile(testDockerComposeYmlIOError)
+		_, err := c.loadResolvedFile(testDockerComposeYmlIOError, "/")
 		if err == nil {
 			t.Fail()
 		}
@@ -521,7 +638,7 @@ func TestConfigLoaderLoadResolvedFile_OpenFileError(t *testing.T) {
 func TestConfigLoaderLoadResolvedFile_VersionError(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		_, err := c.loadResolvedFile(testDockerComposeYmlInvalidVersion)
+		_, err := c.loadResolvedFile(testDockerComposeYmlInvalidVersion, "/")
 		if err == nil {
 			t.Fail()
 		}
@@ -531,7 +648,7 @@ func TestConfigLoaderLoadResolvedFile_VersionError(t *testing.T) {
 func TestConfigLoaderLoadResolvedFile_InterpolationError(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		_, err := c.loadResolvedFile(testDockerComposeYmlInterpolationIssue)
+		_, err := c.loadResolvedFile(testDockerComposeYmlInterpolationIssue, "/")
 		if err == nil {
 			t.Fail()
 		}
@@ -541,7 +658,7 @@ func TestConfigLoaderLoadResolvedFile_InterpolationError(t *testing.T) {
 func TestConfigLoaderLoadResolvedFile_DecodeError(t *testing.T) {
 	withMockFS(func() {
 		c := newTestConfigLoader(nil)
-		_, err := c.loadResolvedFile(testDockerComposeYmlDecodeIssue)
+		_, err := c.loadResolvedFile(testDockerComposeYmlDecodeIssue, "/")
 		if err == nil {
 			t.Fail()
 		}
@@ -662,6 +779,137 @@ func TestNew_ExtendsSuccess(t *testing.T) {
 	})
 }
 
+func TestNew_ComposeSpec(t *testing.T) {
+	withMockFS(func() {
+		c := newTestConfigLoader(map[string]string{
+			"FOO": "bar",
+		})
+		cfParsed, err := c.loadFile(testDockerComposeYmlComposeSpec, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cfParsed.version != nil || cfParsed.name != "myproject" {
+			t.Fail()
+		}
+		if !reflect.DeepEqual(cfParsed.xProperties, XProperties{"x-foo": "bar"}) {
+			t.Error(cfParsed.xProperties)
+		}
+		service1 := cfParsed.services["service1"].service
+		if service1.Image != "ubuntu:latest" || !reflect.DeepEqual(service1.Profiles, []string{"debug"}) {
+			t.Error(service1)
+		}
+		// service2 is included, and extends a service of the included file.
+		if cfParsed.services["service2"].service.Environment["KEY"] != "VALUE" || cfParsed.services["service3"] == nil {
+			t.Error(cfParsed.services)
+		}
+	})
+}
+
+func TestNew_ComposeSpecName(t *testing.T) {
+	withMockFS(func() {
+		c, err := New([]string{testDockerComposeYmlComposeSpec})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if c.Name != "myproject" || len(c.Services) != 3 {
+			t.Error(c)
+		}
+		if len(c.Services["service1"].DependsOn) != 1 {
+			t.Error(c.Services["service1"].DependsOn)
+		}
+	})
+}
+
+func TestNew_MergeKeys(t *testing.T) {
+	withMockFS(func() {
+		c, err := New([]string{testDockerComposeYmlMergeKeys})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		service1, service2, service3 := c.Services["service1"], c.Services["service2"], c.Services["service3"]
+		// Explicit keys take precedence over merged keys, even if the merge key comes after them.
+		if service1.Image != "alpine:latest" || service1.Environment["KEY1"] != "VALUE1" {
+			t.Error(service1)
+		}
+		// The first merged mapping takes precedence.
+		if service2.Image != "ubuntu:latest" || service2.User == nil || *service2.User != "root" {
+			t.Error(service2)
+		}
+		expectedXProperties := XProperties{
+			"x-a": 1,
+			"x-kube-compose": genericMap{
+				"foo": "bar",
+			},
+		}
+		if !reflect.DeepEqual(service2.XProperties, expectedXProperties) {
+			t.Error(service2.XProperties)
+		}
+		if !reflect.DeepEqual(service3.Environment, map[string]string{"KEY2": "VALUE2"}) {
+			t.Error(service3.Environment)
+		}
+	})
+}
+
+func TestNew_IncludeConflict(t *testing.T) {
+	withMockFS(func() {
+		_, err := New([]string{testDockerComposeYmlIncludeConflict})
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_IncludeCycle(t *testing.T) {
+	withMockFS(func() {
+		_, err := New([]string{testDockerComposeYmlIncludeCycle})
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_IncludeOnly(t *testing.T) {
+	withMockFS(func() {
+		c, err := New([]string{testDockerComposeYmlIncludeOnly})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if c.Name != "myproject" || len(c.Services) != 2 || c.Services["service2"] == nil || c.Services["service3"] == nil {
+			t.Error(c)
+		}
+	})
+}
+
+func TestNew_IncludeProjectDirectory(t *testing.T) {
+	withMockFS(func() {
+		_, err := New([]string{testDockerComposeYmlIncludeProjectDirectory})
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestNew_ServiceXPropertiesAndLabels(t *testing.T) {
+	withMockFS(func() {
+		c, err := New([]string{testDockerComposeYmlServiceXProperties})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		service1 := c.Services["service1"]
+		if !reflect.DeepEqual(service1.XProperties, XProperties{"x-a": 1, "x-b": 2}) {
+			t.Error(service1.XProperties)
+		}
+		if !reflect.DeepEqual(service1.Labels, map[string]string{"label1": "value1", "label2": "value2"}) {
+			t.Error(service1.Labels)
+		}
+	})
+}
+
 func TestNew_ExtendsIOError(t *testing.T) {
 	withMockFS(func() {
 		_, err := New([]string{testDockerComposeYmlExtendsIOError})
@@ -711,6 +959,74 @@ func TestNew_Success(t *testing.T) {
 		}
 	})
 }
+func TestNewWithOptions_FileSystem(t *testing.T) {
+	vfs := fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		"/project/docker-compose.yml": {
+			Content: []byte(`version: '2.4'
+services:
+  service1:
+    image: ubuntu:latest
+    volumes: ['./data:/data']
+`),
+		},
+	})
+	c, err := NewWithOptions([]string{"/project/docker-compose.yml"}, &Options{
+		FileSystem: vfs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostPath := c.Services["service1"].Volumes[0].Short.HostPath; hostPath != "/project/data" {
+		t.Error(hostPath)
+	}
+}
+
+func TestNewWithOptions_Stdin(t *testing.T) {
+	vfs := fs.NewInMemoryUnixFileSystem(map[string]fs.InMemoryFile{
+		"/project/base.yml": {
+			Content: []byte(`version: '2.4'
+services:
+  base:
+    environment:
+      VAR1: VAL1
+`),
+		},
+	})
+	c, err := NewWithOptions([]string{StdinFile}, &Options{
+		FileSystem:       vfs,
+		ProjectDirectory: "/project",
+		Stdin: strings.NewReader(`version: '2.4'
+services:
+  service1:
+    image: ubuntu:latest
+    extends:
+      file: base.yml
+      service: base
+    volumes: ['./data:/data']
+`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service1 := c.Services["service1"]
+	if hostPath := service1.Volumes[0].Short.HostPath; hostPath != "/project/data" {
+		t.Error(hostPath)
+	}
+	if service1.Environment["VAR1"] != "VAL1" {
+		t.Error(service1.Environment)
+	}
+}
+
+func TestNewWithOptions_StdinError(t *testing.T) {
+	errExpected := errors.New("stdin error")
+	_, err := NewWithOptions([]string{StdinFile}, &Options{
+		Stdin: iotest.ErrReader(errExpected),
+	})
+	if errors.Cause(err) != errExpected {
+		t.Error(err)
+	}
+}
+
 func TestNew_StandardFileError(t *testing.T) {
 	orig := fs.OS
 	defer func() {
@@ -734,6 +1050,44 @@ func TestGetVersion_Default(t *testing.T) {
 	}
 }
 
+func TestGetVersion_ComposeSpec(t *testing.T) {
+	m := genericMap{
+		"services": genericMap{},
+	}
+	v, err := getVersion(m)
+	if err != nil {
+		t.Error(err)
+	}
+	if v != nil {
+		t.Fail()
+	}
+}
+
+func TestGetVersion_ComposeSpecKeys(t *testing.T) {
+	for _, key := range composeSpecificationKeys {
+		m := genericMap{
+			key: nil,
+		}
+		v, err := getVersion(m)
+		if err != nil {
+			t.Error(err)
+		}
+		if v != nil {
+			t.Error(key, v)
+		}
+	}
+}
+
+func TestLiftV1Services_Success(t *testing.T) {
+	m := genericMap{
+		"service1": genericMap{},
+	}
+	lifted := liftV1Services(m)
+	if !reflect.DeepEqual(lifted, genericMap{"services": m}) {
+		t.Error(lifted)
+	}
+}
+
 func TestGetVersion_FormatError(t *testing.T) {
 	m := genericMap{
 		"version": "",
@@ -861,3 +1215,24 @@ func TestGetXProperties_Success(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestParseStopGracePeriod_Success(t *testing.T) {
+	d, err := parseStopGracePeriod("1m30s")
+	if err != nil || d == nil || *d != 90*time.Second {
+		t.Error(d, err)
+	}
+}
+
+func TestParseStopGracePeriod_Invalid(t *testing.T) {
+	_, err := parseStopGracePeriod("notaduration")
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseStopGracePeriod_Negative(t *testing.T) {
+	_, err := parseStopGracePeriod("-1s")
+	if err == nil {
+		t.Fail()
+	}
+}
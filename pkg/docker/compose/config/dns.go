@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// maxDNSNameservers is the maximum number of nameservers of the DNS config of a Kubernetes pod:
+// https://kubernetes.io/docs/concepts/services-networking/dns-pod-service/#pod-dns-config
+const maxDNSNameservers = 3
+
+// validateDNS validates the dns of a docker compose service, so that an invalid nameserver is reported here instead of when its pod is
+// created.
+func validateDNS(name string, service *Service) error {
+	for _, nameserver := range service.DNS {
+		if net.ParseIP(nameserver) == nil {
+			return fmt.Errorf("service %s has a dns entry %#v that is not an IP address", name, nameserver)
+		}
+	}
+	if len(service.DNS) > maxDNSNameservers {
+		return fmt.Errorf("service %s has %d dns entries, but at most %d are supported", name, len(service.DNS), maxDNSNameservers)
+	}
+	return nil
+}
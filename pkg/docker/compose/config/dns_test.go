@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDNS_Success(t *testing.T) {
+	err := validateDNS("service1", &Service{
+		DNS: []string{"8.8.8.8", "::1", "1.1.1.1"},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateDNS_InvalidIPError(t *testing.T) {
+	err := validateDNS("service1", &Service{
+		DNS: []string{"dns.example.com"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "service1") || !strings.Contains(err.Error(), "dns") {
+		t.Error(err)
+	}
+}
+
+func TestValidateDNS_TooManyNameserversError(t *testing.T) {
+	err := validateDNS("service1", &Service{
+		DNS: []string{"1.1.1.1", "1.0.0.1", "8.8.8.8", "8.8.4.4"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "service1") {
+		t.Error(err)
+	}
+}
+
+func TestNewWithOptions_DNSExtendsTooManyNameserversError(t *testing.T) {
+	_, err := NewWithOptions([]string{StdinFile}, &Options{
+		Stdin: strings.NewReader(`version: '2.4'
+services:
+  service1:
+    image: ubuntu:latest
+    dns: [1.1.1.1, 1.0.0.1]
+    extends:
+      service: service2
+  service2:
+    image: ubuntu:latest
+    dns: [8.8.8.8, 8.8.4.4]
+`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "service1") {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/uber-go/mapdecode"
+)
+
+// ExtraHost is the parsed form of an entry of extra_hosts, which adds a hostname mapping to the /etc/hosts file of a container.
+type ExtraHost struct {
+	Hostname string
+	IP       string
+}
+
+type extraHosts struct {
+	Values []ExtraHost
+}
+
+// Decode parses either the list or the map syntax of extra_hosts.
+// https://docs.docker.com/compose/compose-file/compose-file-v2/#extra_hosts
+func (t *extraHosts) Decode(into mapdecode.Into) error {
+	var intoMap map[string]string
+	err := into(&intoMap)
+	if err == nil {
+		t.Values = make([]ExtraHost, 0, len(intoMap))
+		for hostname, ip := range intoMap {
+			t.Values = append(t.Values, ExtraHost{
+				Hostname: hostname,
+				IP:       ip,
+			})
+		}
+		// Sort so that the order of host aliases is stable.
+		sort.Slice(t.Values, func(i, j int) bool {
+			return t.Values[i].Hostname < t.Values[j].Hostname
+		})
+		return nil
+	}
+	var intoSlice []string
+	err = into(&intoSlice)
+	if err != nil {
+		return err
+	}
+	t.Values = make([]ExtraHost, len(intoSlice))
+	for i, spec := range intoSlice {
+		// Same logic as docker compose, which splits on the first colon so that IPv6 addresses are supported:
+		// https://github.com/docker/compose/blob/1.24.0/compose/config/types.py#L137
+		j := strings.IndexByte(spec, ':')
+		if j < 0 {
+			return fmt.Errorf("invalid extra_hosts entry %#v, should be hostname:ip", spec)
+		}
+		t.Values[i].Hostname = strings.TrimSpace(spec[:j])
+		t.Values[i].IP = strings.TrimSpace(spec[j+1:])
+	}
+	return nil
+}
+
+func parseExtraHosts(input []ExtraHost) ([]ExtraHost, error) {
+	for _, extraHost := range input {
+		if extraHost.Hostname == "" {
+			return nil, fmt.Errorf("extra_hosts has an entry with an empty hostname")
+		}
+		if net.ParseIP(extraHost.IP) == nil {
+			return nil, fmt.Errorf("extra_hosts entry for hostname %s has an invalid IP address %#v", extraHost.Hostname, extraHost.IP)
+		}
+	}
+	return input, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uber-go/mapdecode"
+)
+
+func TestExtraHostsDecode_SliceSuccess(t *testing.T) {
+	var dst extraHosts
+	err := mapdecode.Decode(&dst, []interface{}{
+		"somehost:162.242.195.82",
+		"ipv6host:::1",
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, []ExtraHost{
+		{
+			Hostname: "somehost",
+			IP:       "162.242.195.82",
+		},
+		{
+			Hostname: "ipv6host",
+			IP:       "::1",
+		},
+	}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestExtraHostsDecode_MapSuccess(t *testing.T) {
+	var dst extraHosts
+	err := mapdecode.Decode(&dst, map[interface{}]interface{}{
+		"otherhost": "50.31.209.229",
+		"somehost":  "162.242.195.82",
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, []ExtraHost{
+		{
+			Hostname: "otherhost",
+			IP:       "50.31.209.229",
+		},
+		{
+			Hostname: "somehost",
+			IP:       "162.242.195.82",
+		},
+	}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestExtraHostsDecode_MissingColonError(t *testing.T) {
+	var dst extraHosts
+	err := mapdecode.Decode(&dst, []interface{}{
+		"somehost",
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestExtraHostsDecode_TypeError(t *testing.T) {
+	var dst extraHosts
+	err := mapdecode.Decode(&dst, 1)
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseExtraHosts_InvalidIPError(t *testing.T) {
+	_, err := parseExtraHosts([]ExtraHost{
+		{
+			Hostname: "somehost",
+			IP:       "henkie",
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseExtraHosts_EmptyHostnameError(t *testing.T) {
+	_, err := parseExtraHosts([]ExtraHost{
+		{
+			IP: "127.0.0.1",
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseComposeFileService_DNSSuccess(t *testing.T) {
+	c := newTestConfigLoader(nil)
+	cfService := &composeFileService{
+		DNS: stringOrStringSlice{
+			Values: []string{"8.8.8.8"},
+		},
+		DNSSearch: stringOrStringSlice{
+			Values: []string{"example.com"},
+		},
+		DomainName: "mydomain",
+		ExtraHosts: extraHosts{
+			Values: []ExtraHost{
+				{
+					Hostname: "somehost",
+					IP:       "162.242.195.82",
+				},
+			},
+		},
+		Hostname: "myhost",
+	}
+	cfServiceParsed, err := c.parseComposeFileService("", cfService)
+	if err != nil {
+		t.Error(err)
+	} else {
+		service := cfServiceParsed.service
+		if !reflect.DeepEqual(service.DNS, cfService.DNS.Values) ||
+			!reflect.DeepEqual(service.DNSSearch, cfService.DNSSearch.Values) ||
+			!reflect.DeepEqual(service.ExtraHosts, cfService.ExtraHosts.Values) ||
+			service.DomainName != "mydomain" ||
+			service.Hostname != "myhost" {
+			t.Error(service)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+// IgnoredKey is a key of a docker compose file that was ignored when the file was loaded.
+type IgnoredKey struct {
+	// File is the resolved docker compose file that has the key.
+	File string
+	// Service is the name of the docker compose service that has the key, and is empty if the key is at the root of the file.
+	Service string
+	Key     string
+	// Unsupported is true if the key is valid docker compose configuration that is not supported, and false if the key is unknown.
+	Unsupported bool
+}
+
+func (k *IgnoredKey) String() string {
+	kind := "unknown"
+	if k.Unsupported {
+		kind = "unsupported"
+	}
+	if k.Service == "" {
+		return fmt.Sprintf("file %#v has %s key %s", k.File, kind, k.Key)
+	}
+	return fmt.Sprintf("file %#v: service %s has %s key %s", k.File, k.Service, kind, k.Key)
+}
+
+// composeServiceKeys are all keys of docker compose services, across all versions of the docker compose file format.
+var composeServiceKeys = map[string]bool{
+	"blkio_config": true, "build": true, "cap_add": true, "cap_drop": true, "cgroup": true, "cgroup_parent": true, "command": true,
+	"configs": true, "container_name": true, "cpu_count": true, "cpu_percent": true, "cpu_period": true, "cpu_quota": true,
+	"cpu_rt_period": true, "cpu_rt_runtime": true, "cpu_shares": true, "cpus": true, "cpuset": true, "credential_spec": true,
+	"depends_on": true, "deploy": true, "device_cgroup_rules": true, "devices": true, "dns": true, "dns_opt": true, "dns_search": true,
+	"domainname": true, "entrypoint": true, "env_file": true, "environment": true, "expose": true, "extends": true,
+	"external_links": true, "extra_hosts": true, "group_add": true, "healthcheck": true, "hostname": true, "image": true, "init": true,
+	"ipc": true, "isolation": true, "labels": true, "links": true, "logging": true, "mac_address": true, "mem_limit": true,
+	"mem_reservation": true, "mem_swappiness": true, "memswap_limit": true, "network_mode": true, "networks": true,
+	"oom_kill_disable": true, "oom_score_adj": true, "pid": true, "pids_limit": true, "platform": true, "ports": true,
+	"privileged": true, "profiles": true, "pull_policy": true, "read_only": true, "restart": true, "runtime": true, "scale": true,
+	"secrets": true, "security_opt": true, "shm_size": true, "stdin_open": true, "stop_grace_period": true, "stop_signal": true,
+	"storage_opt": true, "sysctls": true, "tmpfs": true, "tty": true, "ulimits": true, "user": true, "userns_mode": true,
+	"volume_driver": true, "volumes": true, "volumes_from": true, "working_dir": true,
+}
+
+// composeFileKeys are all keys at the root of docker compose files, across all versions of the docker compose file format.
+var composeFileKeys = map[string]bool{
+	"configs": true, "include": true, "name": true, "networks": true, "secrets": true, "services": true, "version": true, "volumes": true,
+}
+
+// supportedServiceKeys are the keys of docker compose services that are supported. The build key is decoded, but images are never built.
+var supportedServiceKeys = getSupportedKeys(reflect.TypeOf(composeFileService{}), map[string]bool{
+	"build": false,
+})
+
+// supportedFileKeys are the keys at the root of docker compose files that are supported. The version key is not decoded by mapdecode.
+var supportedFileKeys = getSupportedKeys(reflect.TypeOf(composeFile{}), map[string]bool{
+	"version": true,
+})
+
+// getSupportedKeys returns the keys of the mapdecode tags of the fields of a struct type, with overrides applied.
+func getSupportedKeys(t reflect.Type, overrides map[string]bool) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapdecode"); key != "" {
+			keys[key] = true
+		}
+	}
+	for key, supported := range overrides {
+		keys[key] = supported
+	}
+	return keys
+}
+
+// getIgnoredKeys returns the keys of a docker compose file and its services that are neither supported nor extension fields. The result
+// is sorted by service and key.
+func getIgnoredKeys(resolvedFile string, dataMap genericMap, v *version.Version) []IgnoredKey {
+	var ignoredKeys []IgnoredKey
+	if !isV1(v) {
+		ignoredKeys = appendIgnoredKeys(ignoredKeys, resolvedFile, "", dataMap, supportedFileKeys, composeFileKeys)
+	}
+	if servicesMap, ok := dataMap["services"].(genericMap); ok {
+		for name, serviceMap := range servicesMap {
+			nameString, ok1 := name.(string)
+			serviceGenericMap, ok2 := serviceMap.(genericMap)
+			if ok1 && ok2 {
+				ignoredKeys = appendIgnoredKeys(ignoredKeys, resolvedFile, nameString, serviceGenericMap, supportedServiceKeys,
+					composeServiceKeys)
+			}
+		}
+	}
+	sort.Slice(ignoredKeys, func(i, j int) bool {
+		if ignoredKeys[i].Service != ignoredKeys[j].Service {
+			return ignoredKeys[i].Service < ignoredKeys[j].Service
+		}
+		return ignoredKeys[i].Key < ignoredKeys[j].Key
+	})
+	return ignoredKeys
+}
+
+func appendIgnoredKeys(ignoredKeys []IgnoredKey, resolvedFile, service string, gm genericMap, supported,
+	known map[string]bool) []IgnoredKey {
+	for key := range gm {
+		keyString, ok := key.(string)
+		if !ok || supported[keyString] || strings.HasPrefix(keyString, "x-") {
+			continue
+		}
+		ignoredKeys = append(ignoredKeys, IgnoredKey{
+			File:        resolvedFile,
+			Service:     service,
+			Key:         keyString,
+			Unsupported: known[keyString],
+		})
+	}
+	return ignoredKeys
+}
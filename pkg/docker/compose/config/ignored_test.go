@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNew_IgnoredKeys(t *testing.T) {
+	withMockFS(func() {
+		c, err := New([]string{testDockerComposeYmlIgnoredKeys})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		file := testDockerComposeYmlIgnoredKeys
+		expected := []IgnoredKey{
+			{File: file, Key: "networks", Unsupported: true},
+			{File: file, Service: "service1", Key: "build", Unsupported: true},
+			{File: file, Service: "service1", Key: "imagee"},
+			{File: file, Service: "service1", Key: "tmpfs", Unsupported: true},
+		}
+		if !reflect.DeepEqual(c.IgnoredKeys, expected) {
+			t.Error(c.IgnoredKeys)
+		}
+	})
+}
+
+func TestGetIgnoredKeys_V1(t *testing.T) {
+	dataMap := genericMap{
+		"services": genericMap{
+			"service1": genericMap{
+				"image": "ubuntu:latest",
+				"links": []interface{}{"service2"},
+			},
+		},
+	}
+	ignoredKeys := getIgnoredKeys("/docker-compose.yml", dataMap, v1)
+	expected := []IgnoredKey{
+		{File: "/docker-compose.yml", Service: "service1", Key: "links", Unsupported: true},
+	}
+	if !reflect.DeepEqual(ignoredKeys, expected) {
+		t.Error(ignoredKeys)
+	}
+}
+
+func TestIgnoredKeyString(t *testing.T) {
+	k1 := &IgnoredKey{File: "/a.yml", Key: "networks", Unsupported: true}
+	if s := k1.String(); s != `file "/a.yml" has unsupported key networks` {
+		t.Error(s)
+	}
+	k2 := &IgnoredKey{File: "/a.yml", Service: "b", Key: "imagee"}
+	if s := k2.String(); s != `file "/a.yml": service b has unknown key imagee` {
+		t.Error(s)
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	version "github.com/hashicorp/go-version"
@@ -12,7 +14,7 @@ type ValueGetter func(name string) (string, bool)
 
 type configInterpolator struct {
 	config      genericMap
-	errorList   []error
+	errorList   InterpolationErrors
 	valueGetter ValueGetter
 	version     *version.Version
 }
@@ -42,8 +44,46 @@ func (p path) pop() path {
 	return p[:len(p)-1]
 }
 
+// String formats p as dot separated keys and indices, for example services.web.environment.DB_URL.
+func (p path) String() string {
+	parts := make([]string, len(p))
+	for i, item := range p {
+		if item.isInt {
+			parts[i] = strconv.Itoa(item.i)
+		} else {
+			parts[i] = item.str
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// InterpolationError is an error substituting the variables of a value of a docker compose file.
+type InterpolationError struct {
+	// Path is the dot separated path of the value, for example services.web.environment.DB_URL.
+	Path string
+	Err  error
+}
+
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// InterpolationErrors are all errors substituting the variables of a docker compose file, sorted by path.
+type InterpolationErrors []*InterpolationError
+
+func (e InterpolationErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid interpolation format:")
+	for _, err := range e {
+		sb.WriteString("\n  ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
 func (c *configInterpolator) run() error {
-	if !c.version.GreaterThan(v1) {
+	if c.version == nil || !c.version.GreaterThan(v1) {
+		// Version 1 files have their services at the root, and the Compose Specification interpolates all sections.
 		c.interpolateSection(c.config, path{})
 	} else {
 		c.interpolateSectionByName("services")
@@ -56,11 +96,13 @@ func (c *configInterpolator) run() error {
 			}
 		}
 	}
-	// Primitive error handling does not report all errors...
-	if len(c.errorList) > 0 {
-		return c.errorList[0]
+	if len(c.errorList) == 0 {
+		return nil
 	}
-	return nil
+	sort.Slice(c.errorList, func(i, j int) bool {
+		return c.errorList[i].Path < c.errorList[j].Path
+	})
+	return c.errorList
 }
 
 func (c *configInterpolator) interpolateSectionByName(name string) {
@@ -82,13 +124,17 @@ func (c *configInterpolator) interpolateSection(configDict genericMap, p path) {
 	}
 }
 
-func (c *configInterpolator) addError(err error, _ path) {
-	c.errorList = append(c.errorList, err)
+func (c *configInterpolator) addError(err error, p path) {
+	c.errorList = append(c.errorList, &InterpolationError{
+		Path: p.String(),
+		Err:  err,
+	})
 }
 
 // InterpolateConfig takes the root of a docker compose file as a generic structure and substitutes variables in it.
 // The implementation substitutes exactly the same sections as docker compose:
 // https://github.com/docker/compose/master/compose/config/config.py.
+// v is nil for files that follow the Compose Specification, in which case all sections are substituted like docker compose v2 does.
 // TODO https://github.com/kube-compose/kube-compose/issues/11 support arbitrary map types instead of genericMap.
 func InterpolateConfig(config genericMap, valueGetter ValueGetter, v *version.Version) error {
 	c := &configInterpolator{
@@ -99,10 +145,23 @@ func InterpolateConfig(config genericMap, valueGetter ValueGetter, v *version.Ve
 	return c.run()
 }
 
+// interpolationSyntax is the syntax of variable substitution, which depends on the version of the docker compose file.
+type interpolationSyntax int
+
+const (
+	// interpolationSyntaxV1 supports $VAR and ${VAR}.
+	interpolationSyntaxV1 interpolationSyntax = iota
+	// interpolationSyntaxV2_1 adds the modifiers -, :-, ? and :? of docker-compose 2.1+.
+	interpolationSyntaxV2_1
+	// interpolationSyntaxComposeSpec adds the modifiers + and :+, and nested variables in defaults and replacements, like
+	// ${A:-${B:-x}}.
+	interpolationSyntaxComposeSpec
+)
+
 type stringInterpolator struct {
 	sb          strings.Builder
 	str         string
-	v           bool
+	syntax      interpolationSyntax
 	valueGetter ValueGetter
 }
 
@@ -144,19 +203,52 @@ func (k *stringInterpolator) processCurlyBraceExpansionWithError(name, errorMsg
 	return nil
 }
 
-func (k *stringInterpolator) processCurlyBraceExpansionWithDefault(name, defaultVal string, treatEmptyAsUnset bool, i int) {
+func (k *stringInterpolator) processCurlyBraceExpansionWithDefault(name, defaultVal string, treatEmptyAsUnset bool, i int) error {
 	value, found := k.valueGetter(name)
 	if !found || (value == "" && treatEmptyAsUnset) {
-		value = defaultVal
+		var err error
+		value, err = k.interpolateNested(defaultVal)
+		if err != nil {
+			return err
+		}
+	}
+	k.sb.WriteString(value)
+	k.advance(i + 1)
+	return nil
+}
+
+func (k *stringInterpolator) processCurlyBraceExpansionWithReplacement(name, replacement string, treatEmptyAsUnset bool, i int) error {
+	value, found := k.valueGetter(name)
+	if found && (value != "" || !treatEmptyAsUnset) {
+		var err error
+		value, err = k.interpolateNested(replacement)
+		if err != nil {
+			return err
+		}
+	} else {
+		value = ""
 	}
 	k.sb.WriteString(value)
 	k.advance(i + 1)
+	return nil
+}
+
+// interpolateNested substitutes the variables of a default or replacement. Only the Compose Specification supports nested variables.
+func (k *stringInterpolator) interpolateNested(str string) (string, error) {
+	if k.syntax < interpolationSyntaxComposeSpec {
+		return str, nil
+	}
+	return interpolate(str, k.valueGetter, k.syntax)
 }
 
 func (k *stringInterpolator) processCurlyBraceExpansion(i int) error {
 	// Process what is between the two curly braces
-	if k.v {
-		j := strings.IndexAny(k.str[1:i], ":?-")
+	if k.syntax >= interpolationSyntaxV2_1 {
+		modifiers := ":?-"
+		if k.syntax >= interpolationSyntaxComposeSpec {
+			modifiers = ":?-+"
+		}
+		j := strings.IndexAny(k.str[1:i], modifiers)
 		if j >= 0 {
 			j++
 			switch {
@@ -165,21 +257,61 @@ func (k *stringInterpolator) processCurlyBraceExpansion(i int) error {
 				case k.str[j+1] == '?':
 					return k.processCurlyBraceExpansionWithError(k.str[1:j], k.str[j+2:i], true, i)
 				case k.str[j+1] == '-':
-					k.processCurlyBraceExpansionWithDefault(k.str[1:j], k.str[j+2:i], true, i)
-					return nil
+					return k.processCurlyBraceExpansionWithDefault(k.str[1:j], k.str[j+2:i], true, i)
+				case k.str[j+1] == '+' && k.syntax >= interpolationSyntaxComposeSpec:
+					return k.processCurlyBraceExpansionWithReplacement(k.str[1:j], k.str[j+2:i], true, i)
 				}
 			case k.str[j] == '?':
 				return k.processCurlyBraceExpansionWithError(k.str[1:j], k.str[j+1:i], false, i)
+			case k.str[j] == '+':
+				return k.processCurlyBraceExpansionWithReplacement(k.str[1:j], k.str[j+1:i], false, i)
 			default:
-				k.processCurlyBraceExpansionWithDefault(k.str[1:j], k.str[j+1:i], false, i)
-				return nil
+				return k.processCurlyBraceExpansionWithDefault(k.str[1:j], k.str[j+1:i], false, i)
 			}
 		}
 	}
+	// Modifiers that were not handled above are not supported by the syntax, and would otherwise silently be treated as part of the name.
+	if j := strings.IndexAny(k.str[1:i], "?-+"); j >= 0 {
+		j++
+		modifier := k.str[j : j+1]
+		if k.str[j-1] == ':' {
+			j--
+			modifier = ":" + modifier
+		}
+		return fmt.Errorf("modifier %s of variable %#v is not supported by the version of the docker compose file", modifier, k.str[1:j])
+	}
 	k.processCurlyBraceExpansionSimple(i)
 	return nil
 }
 
+// findClosingCurlyBrace returns the index of the } that closes the { at the start of k.str, or -1 if there is none. Nested variables are
+// skipped if the syntax supports them.
+func (k *stringInterpolator) findClosingCurlyBrace() int {
+	if k.syntax < interpolationSyntaxComposeSpec {
+		i := strings.IndexRune(k.str[1:], '}')
+		if i < 0 {
+			return -1
+		}
+		return i + 1
+	}
+	depth := 0
+	for i := 1; i < len(k.str); i++ {
+		switch {
+		case k.str[i] == '$' && i+1 < len(k.str) && k.str[i+1] == '$':
+			i++
+		case k.str[i] == '$' && i+1 < len(k.str) && k.str[i+1] == '{':
+			depth++
+			i++
+		case k.str[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
 func (k *stringInterpolator) processAfterDollarSign() error {
 	if k.str[0] == '_' || IsASCIILetter(k.str[0]) {
 		k.processAfterDollarSignSimple()
@@ -187,11 +319,10 @@ func (k *stringInterpolator) processAfterDollarSign() error {
 	}
 	if k.str[0] == '{' {
 		// Scan until '}' to perform substitution...
-		i := strings.IndexRune(k.str[1:], '}')
+		i := k.findClosingCurlyBrace()
 		if i < 0 {
 			return fmt.Errorf("expected }")
 		}
-		i++
 		return k.processCurlyBraceExpansion(i)
 	}
 	if k.str[0] == '$' {
@@ -218,9 +349,23 @@ func (k *stringInterpolator) getResult() string {
 // is otherwise identical to the Python implementation:
 // https://github.com/docker/compose/blob/master/compose/config/interpolation.py
 func Interpolate(str string, valueGetter ValueGetter, v bool) (string, error) {
+	syntax := interpolationSyntaxV1
+	if v {
+		syntax = interpolationSyntaxV2_1
+	}
+	return interpolate(str, valueGetter, syntax)
+}
+
+// InterpolateComposeSpec substitutes variables in str like Interpolate, using the syntax of the Compose Specification. This adds
+// ${VAR+replacement} and ${VAR:+replacement}, and nested variables in defaults and replacements such as ${A:-${B:-x}}.
+func InterpolateComposeSpec(str string, valueGetter ValueGetter) (string, error) {
+	return interpolate(str, valueGetter, interpolationSyntaxComposeSpec)
+}
+
+func interpolate(str string, valueGetter ValueGetter, syntax interpolationSyntax) (string, error) {
 	k := stringInterpolator{
 		str:         str,
-		v:           v,
+		syntax:      syntax,
 		valueGetter: valueGetter,
 	}
 	for {
@@ -251,9 +396,20 @@ func IsASCIIDigit(b byte) bool {
 	return byte('0') <= b && b <= byte('9')
 }
 
+// syntax returns the syntax of variable substitution of the version of the docker compose file.
+func (c *configInterpolator) syntax() interpolationSyntax {
+	switch {
+	case c.version == nil:
+		return interpolationSyntaxComposeSpec
+	case c.version.LessThan(v2_1):
+		return interpolationSyntaxV1
+	}
+	return interpolationSyntaxV2_1
+}
+
 func (c *configInterpolator) interpolateRecursive(obj interface{}, p path) interface{} {
 	if str, ok := obj.(string); ok {
-		str2, err := Interpolate(str, c.valueGetter, !c.version.LessThan(v2_1))
+		str2, err := interpolate(str, c.valueGetter, c.syntax())
 		if err != nil {
 			c.addError(err, p)
 		}
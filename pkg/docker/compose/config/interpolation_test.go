@@ -249,3 +249,130 @@ func TestInterpolateConfig_V3(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestInterpolateComposeSpec_NestedDefault(t *testing.T) {
+	m := map[string]string{
+		"B": "b",
+	}
+	str, err := InterpolateComposeSpec("${A:-${B:-x}}/${A:-${C:-x}}/${A-$$}", mapValueGetter(m))
+	if err != nil || str != "b/x/$" {
+		t.Fatal(str, err)
+	}
+}
+
+func TestInterpolateComposeSpec_NestedError(t *testing.T) {
+	m := map[string]string{}
+	_, err := InterpolateComposeSpec("${A:-${B:?B is required}}", mapValueGetter(m))
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestInterpolateComposeSpec_Replacement(t *testing.T) {
+	m := map[string]string{
+		"SET":   testValue,
+		"EMPTY": "",
+	}
+	str, err := InterpolateComposeSpec("${SET:+x}|${EMPTY:+x}|${UNSET:+x}|${EMPTY+x}|${UNSET+x}|${SET:+${SET}}", mapValueGetter(m))
+	if err != nil || str != "x|||x||"+testValue {
+		t.Fatal(str, err)
+	}
+}
+
+func TestInterpolateComposeSpec_Unclosed(t *testing.T) {
+	m := map[string]string{}
+	_, err := InterpolateComposeSpec("${A:-${B}", mapValueGetter(m))
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestInterpolate_NoNestingBeforeComposeSpec(t *testing.T) {
+	m := map[string]string{
+		"B": "b",
+	}
+	str, err := Interpolate("${A:-${B}}", mapValueGetter(m), true)
+	if err != nil || str != "${B}" {
+		t.Fatal(str, err)
+	}
+}
+
+func TestInterpolate_UnsupportedModifierError(t *testing.T) {
+	m := map[string]string{
+		"A": "a",
+	}
+	for _, str := range []string{"${A:+x}", "${A+x}"} {
+		if _, err := Interpolate(str, mapValueGetter(m), true); err == nil {
+			t.Error(str)
+		}
+	}
+	for _, str := range []string{"${A:-x}", "${A?x}"} {
+		if _, err := Interpolate(str, mapValueGetter(m), false); err == nil {
+			t.Error(str)
+		}
+	}
+}
+
+func TestInterpolateConfig_AllErrors(t *testing.T) {
+	m := map[string]string{}
+	config := genericMap{
+		"version": "2.4",
+		"services": genericMap{
+			"web": genericMap{
+				"environment": genericMap{
+					"DB_URL": "${DB_URL:?DB_URL is required}",
+				},
+				"ports": []interface{}{
+					"$",
+				},
+			},
+		},
+	}
+	err := InterpolateConfig(config, mapValueGetter(m), v2_1)
+	errs, ok := err.(InterpolationErrors)
+	if !ok || len(errs) != 2 || errs[0].Path != "services.web.environment.DB_URL" || errs[1].Path != "services.web.ports.0" {
+		t.Fatal(err)
+	}
+	expected := "invalid interpolation format:\n" +
+		"  services.web.environment.DB_URL: substitution variable \"DB_URL\" has no value or value is empty: \"DB_URL is required\"\n" +
+		"  services.web.ports.0: $ followed by EOF"
+	if err.Error() != expected {
+		t.Error(err)
+	}
+}
+
+func TestInterpolateConfig_V3UnsupportedModifierError(t *testing.T) {
+	m := map[string]string{
+		"TAG": "latest",
+	}
+	config := genericMap{
+		"version": "3.8",
+		"services": genericMap{
+			"web": genericMap{
+				"image": "ubuntu:${TAG:+x}",
+			},
+		},
+	}
+	err := InterpolateConfig(config, mapValueGetter(m), v3_3)
+	errs, ok := err.(InterpolationErrors)
+	if !ok || len(errs) != 1 || errs[0].Path != "services.web.image" {
+		t.Fatal(err)
+	}
+}
+
+func TestInterpolateConfig_ComposeSpec(t *testing.T) {
+	m := map[string]string{
+		"B": "b",
+	}
+	config := genericMap{
+		"name":   "${A:-${B}}",
+		"x-test": "${B:+x}",
+	}
+	err := InterpolateConfig(config, mapValueGetter(m), nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(config, genericMap{"name": "b", "x-test": "x"}) {
+		t.Error(config)
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/uber-go/mapdecode"
+)
+
+// Ulimit is the parsed form of an entry of ulimits. A negative limit means unlimited.
+type Ulimit struct {
+	Soft int64
+	Hard int64
+}
+
+type ulimit struct {
+	Value Ulimit
+}
+
+// Decode parses either the single limit syntax or the soft/hard syntax of an entry of ulimits.
+// https://docs.docker.com/compose/compose-file/compose-file-v2/#ulimits
+func (t *ulimit) Decode(into mapdecode.Into) error {
+	var limit int64
+	err := into(&limit)
+	if err == nil {
+		t.Value.Soft = limit
+		t.Value.Hard = limit
+		return nil
+	}
+	var softHard struct {
+		Soft *int64 `mapdecode:"soft"`
+		Hard *int64 `mapdecode:"hard"`
+	}
+	err = into(&softHard)
+	if err != nil {
+		return err
+	}
+	if softHard.Soft == nil || softHard.Hard == nil {
+		return fmt.Errorf("ulimits entries must either be an integer or have both a soft and a hard limit")
+	}
+	t.Value.Soft = *softHard.Soft
+	t.Value.Hard = *softHard.Hard
+	return nil
+}
+
+type sysctls struct {
+	Values map[string]string
+}
+
+// Decode parses either the list or the map syntax of sysctls.
+// https://docs.docker.com/compose/compose-file/compose-file-v2/#sysctls
+func (t *sysctls) Decode(into mapdecode.Into) error {
+	var intoMap map[string]environmentValue
+	err := into(&intoMap)
+	if err == nil {
+		t.Values = make(map[string]string, len(intoMap))
+		for name, value := range intoMap {
+			switch {
+			case value.StringValue != nil:
+				t.Values[name] = *value.StringValue
+			case value.Int64Value != nil:
+				t.Values[name] = strconv.FormatInt(*value.Int64Value, 10)
+			case value.FloatValue != nil:
+				t.Values[name] = strconv.FormatFloat(*value.FloatValue, 'f', -1, 64)
+			default:
+				return fmt.Errorf("sysctl %s must have a value", name)
+			}
+		}
+		return nil
+	}
+	var intoSlice []string
+	err = into(&intoSlice)
+	if err != nil {
+		return err
+	}
+	t.Values = make(map[string]string, len(intoSlice))
+	for _, spec := range intoSlice {
+		i := strings.IndexByte(spec, '=')
+		if i < 0 {
+			return fmt.Errorf("invalid sysctls entry %#v, should be name=value", spec)
+		}
+		t.Values[spec[:i]] = spec[i+1:]
+	}
+	return nil
+}
+
+// byteValue is used to decode sizes in bytes, such as shm_size, that can be either an integer or a string with a unit.
+type byteValue struct {
+	Value int64
+}
+
+func (t *byteValue) Decode(into mapdecode.Into) error {
+	err := into(&t.Value)
+	if err == nil {
+		return nil
+	}
+	var str string
+	err = into(&str)
+	if err != nil {
+		return err
+	}
+	t.Value, err = parseBytes(str)
+	return err
+}
+
+// parseBytes parses a size in bytes with an optional unit, using the same logic as docker compose:
+// https://github.com/docker/docker-py/blob/4.0.1/docker/utils/utils.py#L377
+func parseBytes(s string) (int64, error) {
+	n := len(s)
+	if n == 0 {
+		return 0, fmt.Errorf("invalid size in bytes %#v", s)
+	}
+	// Allow units to be suffixed by b, for example 64mb.
+	if n >= 2 && isASCIILetter(s[n-2]) && (s[n-1] == 'b' || s[n-1] == 'B') {
+		s = s[:n-1]
+		n--
+	}
+	multiplier := 1.0
+	switch s[n-1] {
+	case 'b', 'B':
+		s = s[:n-1]
+	case 'k', 'K':
+		multiplier = 1024
+		s = s[:n-1]
+	case 'm', 'M':
+		multiplier = 1024 * 1024
+		s = s[:n-1]
+	case 'g', 'G':
+		multiplier = 1024 * 1024 * 1024
+		s = s[:n-1]
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || f*multiplier > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size in bytes %#v", s)
+	}
+	return int64(f * multiplier), nil
+}
+
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
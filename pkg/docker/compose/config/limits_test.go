@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uber-go/mapdecode"
+)
+
+func TestUlimitDecode_SingleSuccess(t *testing.T) {
+	var dst ulimit
+	err := mapdecode.Decode(&dst, 65535)
+	if err != nil {
+		t.Error(err)
+	} else if dst.Value != (Ulimit{Soft: 65535, Hard: 65535}) {
+		t.Error(dst.Value)
+	}
+}
+
+func TestUlimitDecode_SoftHardSuccess(t *testing.T) {
+	var dst ulimit
+	err := mapdecode.Decode(&dst, map[interface{}]interface{}{
+		"soft": 20000,
+		"hard": 40000,
+	})
+	if err != nil {
+		t.Error(err)
+	} else if dst.Value != (Ulimit{Soft: 20000, Hard: 40000}) {
+		t.Error(dst.Value)
+	}
+}
+
+func TestUlimitDecode_MissingHardError(t *testing.T) {
+	var dst ulimit
+	err := mapdecode.Decode(&dst, map[interface{}]interface{}{
+		"soft": 20000,
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestSysctlsDecode_MapSuccess(t *testing.T) {
+	var dst sysctls
+	err := mapdecode.Decode(&dst, map[interface{}]interface{}{
+		"net.core.somaxconn":         1024,
+		"net.ipv4.tcp_syncookies":    "0",
+		"net.ipv4.tcp_rmem_fraction": 0.5,
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, map[string]string{
+		"net.core.somaxconn":         "1024",
+		"net.ipv4.tcp_syncookies":    "0",
+		"net.ipv4.tcp_rmem_fraction": "0.5",
+	}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestSysctlsDecode_SliceSuccess(t *testing.T) {
+	var dst sysctls
+	err := mapdecode.Decode(&dst, []interface{}{
+		"net.core.somaxconn=1024",
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, map[string]string{
+		"net.core.somaxconn": "1024",
+	}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestSysctlsDecode_MissingEqualsError(t *testing.T) {
+	var dst sysctls
+	err := mapdecode.Decode(&dst, []interface{}{
+		"net.core.somaxconn",
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestByteValueDecode_IntegerSuccess(t *testing.T) {
+	var dst byteValue
+	err := mapdecode.Decode(&dst, 1024)
+	if err != nil || dst.Value != 1024 {
+		t.Error(dst.Value, err)
+	}
+}
+
+func TestByteValueDecode_StringSuccess(t *testing.T) {
+	var dst byteValue
+	err := mapdecode.Decode(&dst, "64m")
+	if err != nil || dst.Value != 64*1024*1024 {
+		t.Error(dst.Value, err)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{"100", 100},
+		{"100b", 100},
+		{"2k", 2048},
+		{"1.5KB", 1536},
+		{"64mb", 64 * 1024 * 1024},
+		{"1G", 1024 * 1024 * 1024},
+	}
+	for _, testCase := range testCases {
+		result, err := parseBytes(testCase.input)
+		if err != nil || result != testCase.expected {
+			t.Error(testCase.input, result, err)
+		}
+	}
+}
+
+func TestParseBytes_Error(t *testing.T) {
+	for _, input := range []string{"", "m", "-1", "1t", "abc"} {
+		_, err := parseBytes(input)
+		if err == nil {
+			t.Error(input)
+		}
+	}
+}
@@ -3,7 +3,33 @@ package config
 func merge(into, from *composeFileParsedService) {
 	// Rules here are based on https://docs.docker.com/compose/extends/#adding-and-overriding-configuration
 	mergeStringMaps(into.service.Environment, from.service.Environment)
+	mergeStringMaps(into.service.Labels, from.service.Labels)
+	mergeStringMaps(into.service.Sysctls, from.service.Sysctls)
+	for key, value := range from.service.XProperties {
+		if _, ok := into.service.XProperties[key]; !ok {
+			if into.service.XProperties == nil {
+				into.service.XProperties = XProperties{}
+			}
+			into.service.XProperties[key] = value
+		}
+	}
+	for name, ulimit := range from.service.Ulimits {
+		if _, ok := into.service.Ulimits[name]; !ok {
+			into.service.Ulimits[name] = ulimit
+		}
+	}
+	if into.service.ShmSize == nil {
+		into.service.ShmSize = from.service.ShmSize
+	}
 	into.service.Ports = mergePortBindings(into.service.Ports, from.service.Ports)
+	into.service.Expose = mergePortBindings(into.service.Expose, from.service.Expose)
+	into.service.CapAdd = mergeStringSlices(into.service.CapAdd, from.service.CapAdd)
+	into.service.CapDrop = mergeStringSlices(into.service.CapDrop, from.service.CapDrop)
+	into.service.GroupAdd = mergeStringSlices(into.service.GroupAdd, from.service.GroupAdd)
+	into.service.SecurityOpt = mergeStringSlices(into.service.SecurityOpt, from.service.SecurityOpt)
+	into.service.DNS = mergeStringSlices(into.service.DNS, from.service.DNS)
+	into.service.DNSSearch = mergeStringSlices(into.service.DNSSearch, from.service.DNSSearch)
+	into.service.ExtraHosts = mergeExtraHosts(into.service.ExtraHosts, from.service.ExtraHosts)
 	// TODO https://github.com/kube-compose/kube-compose/issues/48 add missing rules here
 	// TODO https://github.com/kube-compose/kube-compose/issues/164 support merging of volumes
 }
@@ -16,6 +42,37 @@ func mergeStringMaps(intoStringMap, fromStringMap map[string]string) {
 	}
 }
 
+func mergeStringSlices(intoSlice, fromSlice []string) []string {
+	for _, v := range fromSlice {
+		intoSlice = appendStringIfUnique(intoSlice, v)
+	}
+	return intoSlice
+}
+
+func appendStringIfUnique(slice []string, s string) []string {
+	for _, element := range slice {
+		if element == s {
+			return slice
+		}
+	}
+	return append(slice, s)
+}
+
+// mergeExtraHosts merges extra_hosts like a mapping of hostnames to IPs, so that a hostname of into overrides the same hostname of from.
+func mergeExtraHosts(intoExtraHosts, fromExtraHosts []ExtraHost) []ExtraHost {
+	hostnames := make(map[string]bool, len(intoExtraHosts))
+	for _, extraHost := range intoExtraHosts {
+		hostnames[extraHost.Hostname] = true
+	}
+	for _, extraHost := range fromExtraHosts {
+		if !hostnames[extraHost.Hostname] {
+			hostnames[extraHost.Hostname] = true
+			intoExtraHosts = append(intoExtraHosts, extraHost)
+		}
+	}
+	return intoExtraHosts
+}
+
 func mergePortBindings(intoPorts, fromPorts []PortBinding) []PortBinding {
 	for _, v := range fromPorts {
 		intoPorts = appendPortBindingIfUnique(intoPorts, v)
@@ -0,0 +1,81 @@
+package config
+
+import (
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// moveMergeKeysFirst moves the merge keys (<<) of each YAML mapping before the other keys of the mapping. yaml.v2 applies a merge key
+// when it is decoded, so that a merged value overwrites the value of an explicit key that precedes the merge key. The YAML merge key
+// specification requires explicit keys to take precedence regardless of their position, which holds if merge keys come first.
+// The contents are returned unchanged if no mapping needs to be reordered, or if the contents cannot be parsed, in which case yaml.v2
+// reports the error.
+func moveMergeKeysFirst(contents []byte) []byte {
+	var document yamlv3.Node
+	if yamlv3.Unmarshal(contents, &document) != nil {
+		return contents
+	}
+	if !moveMergeKeysFirstInNode(&document) {
+		return contents
+	}
+	result, err := yamlv3.Marshal(&document)
+	if err != nil {
+		return contents
+	}
+	return result
+}
+
+func moveMergeKeysFirstInNode(node *yamlv3.Node) bool {
+	changed := false
+	for _, child := range node.Content {
+		if moveMergeKeysFirstInNode(child) {
+			changed = true
+		}
+	}
+	if node.Kind != yamlv3.MappingNode {
+		return changed
+	}
+	var mergePairs, otherPairs []*yamlv3.Node
+	needsReorder := false
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			// Clear the tag so that the encoder writes a plain << instead of !!merge <<.
+			node.Content[i].Tag = ""
+			mergePairs = append(mergePairs, node.Content[i], node.Content[i+1])
+			needsReorder = needsReorder || len(otherPairs) > 0
+		} else {
+			otherPairs = append(otherPairs, node.Content[i], node.Content[i+1])
+		}
+	}
+	if !needsReorder || hasAliasOfAnchorIn(mergePairs, otherPairs) {
+		// A merge key cannot be moved before the anchor of one of its aliases, because an alias must follow its anchor.
+		return changed
+	}
+	node.Content = append(mergePairs, otherPairs...)
+	return true
+}
+
+// hasAliasOfAnchorIn returns true if and only if the nodes of aliasNodes (and their descendants) have an alias of an anchor that is
+// defined in the nodes of anchorNodes (or their descendants).
+func hasAliasOfAnchorIn(aliasNodes, anchorNodes []*yamlv3.Node) bool {
+	anchors := map[*yamlv3.Node]bool{}
+	walkYAMLNodes(anchorNodes, func(node *yamlv3.Node) {
+		if node.Anchor != "" {
+			anchors[node] = true
+		}
+	})
+	found := false
+	walkYAMLNodes(aliasNodes, func(node *yamlv3.Node) {
+		if node.Kind == yamlv3.AliasNode && anchors[node.Alias] {
+			found = true
+		}
+	})
+	return found
+}
+
+// walkYAMLNodes calls f for each of the nodes and their descendants. Aliases are not followed.
+func walkYAMLNodes(nodes []*yamlv3.Node, f func(node *yamlv3.Node)) {
+	for _, node := range nodes {
+		f(node)
+		walkYAMLNodes(node.Content, f)
+	}
+}
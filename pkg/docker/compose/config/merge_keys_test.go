@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMoveMergeKeysFirst_Reordered(t *testing.T) {
+	contents := moveMergeKeysFirst([]byte(`x-a: &a
+  image: a
+service1:
+  image: b
+  <<: *a
+`))
+	expected := `x-a: &a
+    image: a
+service1:
+    <<: *a
+    image: b
+`
+	if string(contents) != expected {
+		t.Error(string(contents))
+	}
+}
+
+func TestMoveMergeKeysFirst_AnchorInSibling(t *testing.T) {
+	// The merge key cannot be moved before the key whose value defines the anchor of the merge key.
+	original := []byte(`service1:
+  environment: &env
+    A: a
+  <<: {environment: *env}
+`)
+	contents := moveMergeKeysFirst(original)
+	if string(contents) != string(original) {
+		t.Error(string(contents))
+	}
+}
+
+func TestMoveMergeKeysFirst_AnchorInSiblingNested(t *testing.T) {
+	// The merge key of service2 can be moved, but the merge key of service1 cannot.
+	contents := moveMergeKeysFirst([]byte(`service1:
+  image: &image a
+  <<: {image: *image}
+service2:
+  image: b
+  <<: {image: c}
+`))
+	expected := `service1:
+    image: &image a
+    <<: {image: *image}
+service2:
+    <<: {image: c}
+    image: b
+`
+	if string(contents) != expected {
+		t.Error(string(contents))
+	}
+}
+
+func TestMoveMergeKeysFirst_Unchanged(t *testing.T) {
+	original := []byte("service1:\n    <<: {image: a}\n    image:   b\n")
+	contents := moveMergeKeysFirst(original)
+	if string(contents) != string(original) {
+		t.Error(string(contents))
+	}
+}
+
+func TestMoveMergeKeysFirst_SyntaxError(t *testing.T) {
+	original := []byte("service1: [")
+	contents := moveMergeKeysFirst(original)
+	if string(contents) != string(original) {
+		t.Error(string(contents))
+	}
+}
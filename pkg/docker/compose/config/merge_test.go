@@ -6,9 +6,9 @@ import (
 )
 
 func TestMergePortBindings_Basic(t *testing.T) {
-	intoPorts := []PortBinding{{80, 80, 80, "tcp", ""}}
-	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", ""}}
-	expected := []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8000, 8000, "tcp", ""}}
+	intoPorts := []PortBinding{{80, 80, 80, "tcp", "", "", ""}}
+	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", "", "", ""}}
+	expected := []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8000, 8000, "tcp", "", "", ""}}
 
 	intoPorts = mergePortBindings(intoPorts, fromPorts)
 	if !reflect.DeepEqual(intoPorts, expected) {
@@ -17,9 +17,9 @@ func TestMergePortBindings_Basic(t *testing.T) {
 }
 
 func TestMergePortBindings_Duplicate(t *testing.T) {
-	intoPorts := []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8000, 8000, "tcp", ""}}
-	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", ""}}
-	expected := []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8000, 8000, "tcp", ""}}
+	intoPorts := []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8000, 8000, "tcp", "", "", ""}}
+	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", "", "", ""}}
+	expected := []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8000, 8000, "tcp", "", "", ""}}
 
 	intoPorts = mergePortBindings(intoPorts, fromPorts)
 	if !reflect.DeepEqual(intoPorts, expected) {
@@ -28,9 +28,9 @@ func TestMergePortBindings_Duplicate(t *testing.T) {
 }
 
 func TestMergePortBindings_DuplicateInternalOnly(t *testing.T) {
-	intoPorts := []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8001, 8001, "tcp", ""}}
-	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", ""}}
-	expected := []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8001, 8001, "tcp", ""}, {8000, 8000, 8000, "tcp", ""}}
+	intoPorts := []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8001, 8001, "tcp", "", "", ""}}
+	fromPorts := []PortBinding{{8000, 8000, 8000, "tcp", "", "", ""}}
+	expected := []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8001, 8001, "tcp", "", "", ""}, {8000, 8000, 8000, "tcp", "", "", ""}}
 
 	intoPorts = mergePortBindings(intoPorts, fromPorts)
 	if !reflect.DeepEqual(intoPorts, expected) {
@@ -83,21 +83,21 @@ func TestMerge_Basic(t *testing.T) {
 	serviceA := &composeFileParsedService{
 		service: &Service{
 			Environment: map[string]string{"a": "b"},
-			Ports:       []PortBinding{{80, 80, 80, "tcp", ""}},
+			Ports:       []PortBinding{{80, 80, 80, "tcp", "", "", ""}},
 		},
 	}
 
 	serviceB := &composeFileParsedService{
 		service: &Service{
 			Environment: map[string]string{"b": "c"},
-			Ports:       []PortBinding{{8000, 8000, 8000, "tcp", ""}},
+			Ports:       []PortBinding{{8000, 8000, 8000, "tcp", "", "", ""}},
 		},
 	}
 
 	expected := &composeFileParsedService{
 		service: &Service{
 			Environment: map[string]string{"a": "b", "b": "c"},
-			Ports:       []PortBinding{{80, 80, 80, "tcp", ""}, {8000, 8000, 8000, "tcp", ""}},
+			Ports:       []PortBinding{{80, 80, 80, "tcp", "", "", ""}, {8000, 8000, 8000, "tcp", "", "", ""}},
 		},
 	}
 
@@ -106,3 +106,49 @@ func TestMerge_Basic(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestMergeStringSlices_Duplicate(t *testing.T) {
+	into := []string{"NET_ADMIN", "SYS_TIME"}
+	from := []string{"SYS_TIME", "SYS_PTRACE"}
+	expected := []string{"NET_ADMIN", "SYS_TIME", "SYS_PTRACE"}
+
+	into = mergeStringSlices(into, from)
+	if !reflect.DeepEqual(into, expected) {
+		t.Error(into)
+	}
+}
+
+func TestMerge_DNSAndExtraHosts(t *testing.T) {
+	into := &composeFileParsedService{
+		service: &Service{
+			DNS:       []string{"8.8.8.8"},
+			DNSSearch: []string{"example.com"},
+			ExtraHosts: []ExtraHost{
+				{Hostname: "host1", IP: "10.0.0.1"},
+			},
+		},
+	}
+	from := &composeFileParsedService{
+		service: &Service{
+			DNS:       []string{"8.8.8.8", "8.8.4.4"},
+			DNSSearch: []string{"example.org"},
+			ExtraHosts: []ExtraHost{
+				{Hostname: "host1", IP: "10.0.0.2"},
+				{Hostname: "host2", IP: "10.0.0.3"},
+			},
+		},
+	}
+	merge(into, from)
+	if !reflect.DeepEqual(into.service.DNS, []string{"8.8.8.8", "8.8.4.4"}) {
+		t.Error(into.service.DNS)
+	}
+	if !reflect.DeepEqual(into.service.DNSSearch, []string{"example.com", "example.org"}) {
+		t.Error(into.service.DNSSearch)
+	}
+	if !reflect.DeepEqual(into.service.ExtraHosts, []ExtraHost{
+		{Hostname: "host1", IP: "10.0.0.1"},
+		{Hostname: "host2", IP: "10.0.0.3"},
+	}) {
+		t.Error(into.service.ExtraHosts)
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"github.com/kube-compose/kube-compose/pkg/expanduser"
 )
 
-func expandPath(workingDirChild, path string) string {
+// expandPath expands a tilde prefix of path, and resolves path relative to dir if it is relative.
+func expandPath(dir, path string) string {
 	path = expanduser.ExpandUser(path)
 	if !filepath.IsAbs(path) {
-		path = filepath.Join(filepath.Dir(workingDirChild), path)
+		path = filepath.Join(dir, path)
 	}
 	return path
 }
@@ -9,11 +9,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-// TODO https://github.com/kube-compose/kube-compose/issues/46
+// portBindingSpecRegexp matches the short syntax of ports. The host can be an IPv6 address enclosed in brackets, as in [::1]:8080:80.
 var portBindingSpecRegexp = regexp.MustCompile(
 	"^" + // Match full string
 		"(?:" + // External part
-		"(?:(?P<host>[a-fA-F\\d.:]+?):)?" + // IP address
+		"(?:(?:\\[(?P<hostIPv6>[a-fA-F\\d.:]+)\\]|(?P<host>[a-fA-F\\d.:]+?)):)?" + // IP address
 		"(?P<externalMin>[\\d]*)(?:-(?P<externalMax>\\d+))?:" + // External range
 		")?" +
 		"(?P<internalMin>\\d+)(?:-(?P<internalMax>\\d+))?" + // Internal range
@@ -32,8 +32,13 @@ type PortBinding struct {
 	ExternalMax int32
 	// one of "udp", "tcp" and "sctp"
 	Protocol string
-	// the host (see docker for more details). Can be an empty string if the host was not set in the specification.
+	// the host (see docker for more details). Can be an empty string if the host was not set in the specification. IPv6 addresses are
+	// not enclosed in brackets.
 	Host string
+	// the name of the port, which can only be set using the long syntax. Can be an empty string.
+	Name string
+	// the application protocol of the port (e.g. "http"), which can only be set using the long syntax. Can be an empty string.
+	AppProtocol string
 }
 
 type portBindingParser struct {
@@ -59,6 +64,9 @@ func (parser *portBindingParser) parseInternal(matchMap map[string]string) error
 		if err != nil {
 			return err
 		}
+		if internalMax < internalMin {
+			return fmt.Errorf("invalid port range %d-%d", internalMin, internalMax)
+		}
 		for i := internalMin; i <= internalMax; i++ {
 			parser.internal = append(parser.internal, i)
 		}
@@ -81,6 +89,11 @@ func (parser *portBindingParser) parseExternal(matchMap map[string]string) error
 			if err != nil {
 				return err
 			}
+			if externalMax < externalMin {
+				return fmt.Errorf("invalid port range %d-%d", externalMin, externalMax)
+			}
+			// Like docker compose, a range of external ports is allowed if there is a single internal port, in which case docker chooses
+			// an available port from the range.
 			if len(parser.internal) == 1 {
 				parser.result = append(parser.result, PortBinding{
 					Internal:    parser.internal[0],
@@ -132,6 +145,7 @@ func (parser *portBindingParser) buildResult() error {
 //  - "127.0.0.1:5000-5010:5000-5010"
 //  - "6060:6060/udp"
 //  - "12400-12500:1240"
+//  - "[::1]:8080:80"
 func parsePortBindings(spec string, portBindings []PortBinding) ([]PortBinding, error) {
 	parser := portBindingParser{
 		result: portBindings,
@@ -143,6 +157,9 @@ func parsePortBindings(spec string, portBindings []PortBinding) ([]PortBinding,
 	matchMap := util.BuildRegexpMatchMap(portBindingSpecRegexp, matches)
 
 	parser.host = matchMap["host"]
+	if parser.host == "" {
+		parser.host = matchMap["hostIPv6"]
+	}
 	parser.protocol = matchMap["protocol"]
 	if parser.protocol == "" {
 		parser.protocol = "tcp"
@@ -176,14 +193,81 @@ func parsePortUint(portStr string) (int32, error) {
 	return int32(port), nil
 }
 
+// parsePortLongSyntax parses the long syntax of a port by converting it to the short syntax, so that ranges are treated the same.
+// https://github.com/compose-spec/compose-spec/blob/master/05-services.md#long-syntax-3
+func parsePortLongSyntax(long *portLongSyntax, portBindings []PortBinding) ([]PortBinding, error) {
+	if long.Target == nil {
+		return nil, fmt.Errorf("port is missing the target")
+	}
+	protocol := long.Protocol
+	switch protocol {
+	case "":
+		protocol = "tcp"
+	case "tcp", "udp", "sctp":
+	default:
+		return nil, fmt.Errorf("port %s has invalid protocol %q, should be one of tcp, udp and sctp", long.Target.Value, protocol)
+	}
+	spec := long.Target.Value + "/" + protocol
+	if long.Published != nil {
+		spec = long.Published.Value + ":" + spec
+	}
+	if long.HostIP != "" {
+		if long.Published == nil {
+			spec = ":" + spec
+		}
+		spec = "[" + long.HostIP + "]:" + spec
+	}
+	n := len(portBindings)
+	portBindings, err := parsePortBindings(spec, portBindings)
+	if err != nil {
+		return nil, err
+	}
+	if long.Name != "" && len(portBindings)-n > 1 {
+		return nil, fmt.Errorf("port %s has name %q, but a port with a name cannot have a range of target ports", long.Target.Value, long.Name)
+	}
+	for i := n; i < len(portBindings); i++ {
+		portBindings[i].Name = long.Name
+		portBindings[i].AppProtocol = long.AppProtocol
+	}
+	return portBindings, nil
+}
+
 func parsePorts(inputs []port) ([]PortBinding, error) {
 	portBindings := []PortBinding{}
 	for _, input := range inputs {
+		var err error
+		if input.Long != nil {
+			portBindings, err = parsePortLongSyntax(input.Long, portBindings)
+		} else {
+			portBindings, err = parsePortBindings(input.Value, portBindings)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return portBindings, nil
+}
+
+// parseExpose parses the expose of a docker compose service. The syntax is the same as that of ports, except that ports cannot be
+// published.
+// https://docs.docker.com/compose/compose-file/compose-file-v2/#expose
+func parseExpose(inputs []port) ([]PortBinding, error) {
+	portBindings := []PortBinding{}
+	for _, input := range inputs {
+		if input.Long != nil {
+			return nil, fmt.Errorf("invalid expose, should be port[-port][/protocol] but got an object")
+		}
+		n := len(portBindings)
 		var err error
 		portBindings, err = parsePortBindings(input.Value, portBindings)
 		if err != nil {
 			return nil, err
 		}
+		for i := n; i < len(portBindings); i++ {
+			if portBindings[i].ExternalMin >= 0 {
+				return nil, fmt.Errorf("invalid expose %q, should be port[-port][/protocol]", input.Value)
+			}
+		}
 	}
 	return portBindings, nil
 }
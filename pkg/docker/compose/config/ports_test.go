@@ -129,6 +129,39 @@ func TestParsePortBindings_SuccessWithoutExternal(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestParsePortBindings_IPv6(t *testing.T) {
+	expected := []PortBinding{
+		{
+			Internal:    80,
+			ExternalMin: 8080,
+			ExternalMax: 8080,
+			Protocol:    "tcp",
+			Host:        "::1",
+		},
+	}
+	actual, err := parsePortBindings("[::1]:8080:80", nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(actual, expected) {
+		t.Error(actual)
+	}
+}
+
+func TestParsePortBindings_InvalidInternalRange(t *testing.T) {
+	_, err := parsePortBindings("8001-8000", nil)
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParsePortBindings_InvalidExternalRange(t *testing.T) {
+	_, err := parsePortBindings("8001-8000:80", nil)
+	if err == nil {
+		t.Fail()
+	}
+}
+
 func TestParsePortBindings_Error(t *testing.T) {
 	_, err := parsePortBindings("!", nil)
 	if err == nil {
@@ -172,3 +205,173 @@ func TestParsePorts_Error(t *testing.T) {
 func TestParsePorts_Success(t *testing.T) {
 	_, _ = parsePorts([]port{})
 }
+
+func TestParsePorts_LongSyntax(t *testing.T) {
+	expected := []PortBinding{
+		{
+			Internal:    80,
+			ExternalMin: 8080,
+			ExternalMax: 8090,
+			Protocol:    "udp",
+			Host:        "::1",
+			Name:        "web",
+			AppProtocol: "http",
+		},
+		{
+			Internal:    443,
+			ExternalMin: -1,
+			ExternalMax: -1,
+			Protocol:    "tcp",
+		},
+	}
+	actual, err := parsePorts([]port{
+		{
+			Long: &portLongSyntax{
+				AppProtocol: "http",
+				HostIP:      "::1",
+				Name:        "web",
+				Protocol:    "udp",
+				Published:   &port{Value: "8080-8090"},
+				Target:      &port{Value: "80"},
+			},
+		},
+		{
+			Long: &portLongSyntax{
+				Target: &port{Value: "443"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(actual, expected) {
+		t.Error(actual)
+	}
+}
+
+func TestParsePorts_LongSyntaxNamedRangeError(t *testing.T) {
+	_, err := parsePorts([]port{
+		{
+			Long: &portLongSyntax{
+				Name:   "web",
+				Target: &port{Value: "80-81"},
+			},
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParsePorts_LongSyntaxHostIPWithoutPublished(t *testing.T) {
+	actual, err := parsePorts([]port{
+		{
+			Long: &portLongSyntax{
+				HostIP: "127.0.0.1",
+				Target: &port{Value: "80"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(actual) != 1 || actual[0].Host != "127.0.0.1" || actual[0].ExternalMin != -1 {
+		t.Error(actual)
+	}
+}
+
+func TestParsePorts_LongSyntaxMissingTarget(t *testing.T) {
+	_, err := parsePorts([]port{
+		{
+			Long: &portLongSyntax{},
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParsePorts_LongSyntaxInvalidProtocol(t *testing.T) {
+	_, err := parsePorts([]port{
+		{
+			Long: &portLongSyntax{
+				Protocol: "http",
+				Target:   &port{Value: "80"},
+			},
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseExpose_Success(t *testing.T) {
+	expected := []PortBinding{
+		{
+			Internal:    3000,
+			ExternalMin: -1,
+			ExternalMax: -1,
+			Protocol:    "tcp",
+		},
+		{
+			Internal:    8000,
+			ExternalMin: -1,
+			ExternalMax: -1,
+			Protocol:    "udp",
+		},
+		{
+			Internal:    8001,
+			ExternalMin: -1,
+			ExternalMax: -1,
+			Protocol:    "udp",
+		},
+	}
+	actual, err := parseExpose([]port{
+		{
+			Value: "3000",
+		},
+		{
+			Value: "8000-8001/udp",
+		},
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(actual, expected) {
+		t.Logf("ports1: %+v\n", actual)
+		t.Logf("ports2: %+v\n", expected)
+		t.Fail()
+	}
+}
+
+func TestParseExpose_PublishedError(t *testing.T) {
+	_, err := parseExpose([]port{
+		{
+			Value: "8080:80",
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseExpose_LongSyntaxError(t *testing.T) {
+	_, err := parseExpose([]port{
+		{
+			Long: &portLongSyntax{
+				Target: &port{Value: "80"},
+			},
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestParseExpose_InvalidError(t *testing.T) {
+	_, err := parseExpose([]port{
+		{
+			Value: "asdf",
+		},
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
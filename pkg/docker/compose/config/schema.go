@@ -27,6 +27,57 @@ func (t *stringOrStringSlice) Decode(into mapdecode.Into) error {
 	return nil
 }
 
+// commandLine is the type used to decode the command and entrypoint of a docker compose service. Strings are split into words like
+// docker compose does. An empty slice is never decoded as nil, so that an empty command line can be distinguished from an absent one.
+type commandLine struct {
+	Values []string
+}
+
+func (t *commandLine) Decode(into mapdecode.Into) error {
+	err := into(&t.Values)
+	if err == nil {
+		if t.Values == nil {
+			t.Values = []string{}
+		}
+		return nil
+	}
+	var str string
+	err = into(&str)
+	if err != nil {
+		return err
+	}
+	t.Values, err = shlexSplit(str)
+	return err
+}
+
+// stringOrNumberSlice is used to decode lists whose elements can be either strings or numbers, such as group_add. Numbers are
+// formatted as strings.
+type stringOrNumberSlice struct {
+	Values []string
+}
+
+func (t *stringOrNumberSlice) Decode(into mapdecode.Into) error {
+	var intoSlice []environmentValue
+	err := into(&intoSlice)
+	if err != nil {
+		return err
+	}
+	t.Values = make([]string, len(intoSlice))
+	for i, value := range intoSlice {
+		switch {
+		case value.StringValue != nil:
+			t.Values[i] = *value.StringValue
+		case value.Int64Value != nil:
+			t.Values[i] = strconv.FormatInt(*value.Int64Value, 10)
+		case value.FloatValue != nil:
+			t.Values[i] = strconv.FormatFloat(*value.FloatValue, 'f', -1, 64)
+		default:
+			return fmt.Errorf("list must only contain strings and numbers")
+		}
+	}
+	return nil
+}
+
 type HealthcheckTest struct {
 	Values []string
 }
@@ -173,6 +224,35 @@ func (t *environment) Decode(into mapdecode.Into) error {
 	return err
 }
 
+// labels is used to decode the labels of a docker compose service, which have the same syntax as environment variables. Labels without a
+// value have the empty string as their value.
+type labels struct {
+	Values map[string]string
+}
+
+func (t *labels) Decode(into mapdecode.Into) error {
+	var env environment
+	err := env.Decode(into)
+	if err != nil {
+		return err
+	}
+	t.Values = make(map[string]string, len(env.Values))
+	for _, pair := range env.Values {
+		var value string
+		switch {
+		case pair.Value == nil:
+		case pair.Value.StringValue != nil:
+			value = *pair.Value.StringValue
+		case pair.Value.Int64Value != nil:
+			value = strconv.FormatInt(*pair.Value.Int64Value, 10)
+		case pair.Value.FloatValue != nil:
+			value = strconv.FormatFloat(*pair.Value.FloatValue, 'g', -1, 64)
+		}
+		t.Values[pair.Name] = value
+	}
+	return nil
+}
+
 type extendsHelper struct {
 	File    *string `mapdecode:"file"`
 	Service string  `mapdecode:"service"`
@@ -201,8 +281,22 @@ func (e *extends) Decode(into mapdecode.Into) error {
 	return nil
 }
 
+// portLongSyntax is the long syntax of a port of a docker compose service.
+// https://github.com/compose-spec/compose-spec/blob/master/05-services.md#long-syntax-3
+type portLongSyntax struct {
+	AppProtocol string `mapdecode:"app_protocol"`
+	HostIP      string `mapdecode:"host_ip"`
+	Name        string `mapdecode:"name"`
+	Protocol    string `mapdecode:"protocol"`
+	Published   *port  `mapdecode:"published"`
+	Target      *port  `mapdecode:"target"`
+}
+
+// port is an element of the ports or expose of a docker compose service. Value is the short syntax, and Long is set instead if the port
+// uses the long syntax.
 type port struct {
 	Value string
+	Long  *portLongSyntax
 }
 
 func (p *port) Decode(into mapdecode.Into) error {
@@ -214,8 +308,17 @@ func (p *port) Decode(into mapdecode.Into) error {
 	}
 	strVal := ""
 	err = into(&strVal)
-	p.Value = strVal
-	return err
+	if err == nil {
+		p.Value = strVal
+		return nil
+	}
+	var long portLongSyntax
+	err = into(&long)
+	if err != nil {
+		return err
+	}
+	p.Long = &long
+	return nil
 }
 
 // ServiceVolume is the type used to encode each volume of a docker compose service.
@@ -241,26 +344,79 @@ type composeFileService struct {
 		Context    string `mapdecode:"context"`
 		Dockerfile string `mapdecode:"dockerfile"`
 	} `mapdecode:"build"`
-	// TODO https://github.com/kube-compose/kube-compose/issues/153 interpret string command/entrypoint correctly
-	Command   stringOrStringSlice `mapdecode:"command"`
-	DependsOn *dependsOn          `mapdecode:"depends_on"`
-	// TODO https://github.com/kube-compose/kube-compose/issues/153 interpret string command/entrypoint correctly
-	// TODO https://github.com/kube-compose/kube-compose/issues/157 just use []string instead of *[]string to distinguish between empty slice
-	// and absent slice.
-	Entrypoint  *stringOrStringSlice `mapdecode:"entrypoint"`
-	Environment environment          `mapdecode:"environment"`
-	Extends     *extends             `mapdecode:"extends"`
-	Healthcheck *ServiceHealthcheck  `mapdecode:"healthcheck"`
-	Image       string               `mapdecode:"image"`
-	Ports       []port               `mapdecode:"ports"`
-	Privileged  bool                 `mapdecode:"privileged"`
-	User        *string              `mapdecode:"user"`
-	Volumes     []ServiceVolume      `mapdecode:"volumes"`
-	WorkingDir  string               `mapdecode:"working_dir"`
-	Restart     string               `mapdecode:"restart"`
+	CapAdd          []string            `mapdecode:"cap_add"`
+	CapDrop         []string            `mapdecode:"cap_drop"`
+	Command         commandLine         `mapdecode:"command"`
+	DependsOn       *dependsOn          `mapdecode:"depends_on"`
+	DNS             stringOrStringSlice `mapdecode:"dns"`
+	DNSSearch       stringOrStringSlice `mapdecode:"dns_search"`
+	DomainName      string              `mapdecode:"domainname"`
+	Entrypoint      *commandLine        `mapdecode:"entrypoint"`
+	Environment     environment         `mapdecode:"environment"`
+	Expose          []port              `mapdecode:"expose"`
+	Extends         *extends            `mapdecode:"extends"`
+	ExtraHosts      extraHosts          `mapdecode:"extra_hosts"`
+	GroupAdd        stringOrNumberSlice `mapdecode:"group_add"`
+	Healthcheck     *ServiceHealthcheck `mapdecode:"healthcheck"`
+	Hostname        string              `mapdecode:"hostname"`
+	Image           string              `mapdecode:"image"`
+	Init            bool                `mapdecode:"init"`
+	Labels          labels              `mapdecode:"labels"`
+	Ports           []port              `mapdecode:"ports"`
+	Privileged      bool                `mapdecode:"privileged"`
+	Profiles        []string            `mapdecode:"profiles"`
+	ReadOnly        bool                `mapdecode:"read_only"`
+	SecurityOpt     []string            `mapdecode:"security_opt"`
+	ShmSize         *byteValue          `mapdecode:"shm_size"`
+	StopGracePeriod *string             `mapdecode:"stop_grace_period"`
+	StopSignal      string              `mapdecode:"stop_signal"`
+	Sysctls         sysctls             `mapdecode:"sysctls"`
+	Ulimits         map[string]*ulimit  `mapdecode:"ulimits"`
+	User            *string             `mapdecode:"user"`
+	UsernsMode      string              `mapdecode:"userns_mode"`
+	Volumes         []ServiceVolume     `mapdecode:"volumes"`
+	WorkingDir      string              `mapdecode:"working_dir"`
+	Restart         string              `mapdecode:"restart"`
+
+	// xProperties are the extension fields of the docker compose service, which are not decoded by mapdecode.
+	xProperties XProperties
 }
 
 type composeFile struct {
+	Include  []include                      `mapdecode:"include"`
+	Name     string                         `mapdecode:"name"`
 	Services map[string]*composeFileService `mapdecode:"services"`
 	Volumes  map[string]interface{}         `mapdecode:"volumes"`
 }
+
+type includeHelper struct {
+	EnvFile          stringOrStringSlice `mapdecode:"env_file"`
+	Path             stringOrStringSlice `mapdecode:"path"`
+	ProjectDirectory *string             `mapdecode:"project_directory"`
+}
+
+// include is an element of the include key of the Compose Specification, which is either a path or an object.
+type include struct {
+	EnvFile          []string
+	Path             []string
+	ProjectDirectory *string
+}
+
+// Used by mapdecode package
+func (i *include) Decode(into mapdecode.Into) error {
+	var path string
+	err := into(&path)
+	if err == nil {
+		i.Path = []string{path}
+		return nil
+	}
+	var iHelper includeHelper
+	err = into(&iHelper)
+	if err != nil {
+		return err
+	}
+	i.EnvFile = iHelper.EnvFile.Values
+	i.Path = iHelper.Path.Values
+	i.ProjectDirectory = iHelper.ProjectDirectory
+	return nil
+}
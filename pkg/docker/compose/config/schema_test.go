@@ -32,6 +32,30 @@ func TestPortDecode_SuccessString(t *testing.T) {
 	}
 }
 
+func TestPortDecode_SuccessLongSyntax(t *testing.T) {
+	src := map[interface{}]interface{}{
+		"target":    80,
+		"published": "8080-8081",
+		"name":      "web",
+	}
+	var dst port
+	err := mapdecode.Decode(&dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Long == nil || dst.Long.Target.Value != "80" || dst.Long.Published.Value != "8080-8081" || dst.Long.Name != "web" {
+		t.Error(dst)
+	}
+}
+
+func TestPortDecode_Error(t *testing.T) {
+	var dst port
+	err := mapdecode.Decode(&dst, true)
+	if err == nil {
+		t.Fail()
+	}
+}
+
 func TestExtendsDecode_SuccessString(t *testing.T) {
 	src := "my-service"
 	var dst extends
@@ -428,3 +452,89 @@ func TestServiceVolumeDecode_Error(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestCommandLineDecode_StringSuccess(t *testing.T) {
+	var dst commandLine
+	err := mapdecode.Decode(&dst, "npm run test -- --ci")
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, []string{"npm", "run", "test", "--", "--ci"}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestCommandLineDecode_EmptySliceSuccess(t *testing.T) {
+	var dst commandLine
+	err := mapdecode.Decode(&dst, []interface{}{})
+	if err != nil {
+		t.Error(err)
+	} else if dst.Values == nil || len(dst.Values) != 0 {
+		t.Error(dst.Values)
+	}
+}
+
+func TestCommandLineDecode_StringError(t *testing.T) {
+	var dst commandLine
+	err := mapdecode.Decode(&dst, "echo 'unterminated")
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestCommandLineDecode_TypeError(t *testing.T) {
+	var dst commandLine
+	err := mapdecode.Decode(&dst, map[string]interface{}{})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestStringOrNumberSliceDecode_Success(t *testing.T) {
+	var dst stringOrNumberSlice
+	err := mapdecode.Decode(&dst, []interface{}{"mail", 1000, 1.5})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, []string{"mail", "1000", "1.5"}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestStringOrNumberSliceDecode_TypeError(t *testing.T) {
+	var dst stringOrNumberSlice
+	err := mapdecode.Decode(&dst, []interface{}{true})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestLabelsDecode_SliceSuccess(t *testing.T) {
+	var dst labels
+	err := mapdecode.Decode(&dst, []interface{}{
+		"com.example.description=Accounting webapp",
+		"com.example.empty",
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, map[string]string{
+		"com.example.description": "Accounting webapp",
+		"com.example.empty":       "",
+	}) {
+		t.Error(dst.Values)
+	}
+}
+
+func TestLabelsDecode_MapSuccess(t *testing.T) {
+	var dst labels
+	err := mapdecode.Decode(&dst, map[interface{}]interface{}{
+		"com.example.number": 1,
+		"com.example.null":   nil,
+	})
+	if err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(dst.Values, map[string]string{
+		"com.example.number": "1",
+		"com.example.null":   "",
+	}) {
+		t.Error(dst.Values)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+type shlexState int
+
+const (
+	shlexStateWhitespace shlexState = iota
+	shlexStateWord
+	shlexStateSingleQuote
+	shlexStateDoubleQuote
+)
+
+func isShlexWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// shlexSplit splits a string into words using the same rules as Python's shlex.split (in POSIX mode and without comments). docker
+// compose uses shlex.split to split commands and entrypoints that are specified as a string:
+// https://github.com/docker/docker-py/blob/4.0.1/docker/utils/utils.py#L457
+// https://docs.python.org/3/library/shlex.html#parsing-rules
+func shlexSplit(s string) ([]string, error) {
+	words := []string{}
+	var sb strings.Builder
+	state := shlexStateWhitespace
+	n := len(s)
+	for i := 0; i < n; i++ {
+		b := s[i]
+		switch state {
+		case shlexStateWhitespace, shlexStateWord:
+			switch {
+			case isShlexWhitespace(b):
+				if state == shlexStateWord {
+					words = append(words, sb.String())
+					sb.Reset()
+					state = shlexStateWhitespace
+				}
+				continue
+			case b == '\\':
+				if i+1 >= n {
+					return nil, fmt.Errorf("no escaped character in %#v", s)
+				}
+				i++
+				sb.WriteByte(s[i])
+			case b == '\'':
+				state = shlexStateSingleQuote
+				continue
+			case b == '"':
+				state = shlexStateDoubleQuote
+				continue
+			default:
+				sb.WriteByte(b)
+			}
+			state = shlexStateWord
+		case shlexStateSingleQuote:
+			if b == '\'' {
+				state = shlexStateWord
+			} else {
+				sb.WriteByte(b)
+			}
+		case shlexStateDoubleQuote:
+			switch {
+			case b == '"':
+				state = shlexStateWord
+			case b == '\\' && i+1 < n && (s[i+1] == '"' || s[i+1] == '\\'):
+				// Within double quotes the escape character only escapes the quote and itself.
+				i++
+				sb.WriteByte(s[i])
+			default:
+				sb.WriteByte(b)
+			}
+		}
+	}
+	switch state {
+	case shlexStateSingleQuote, shlexStateDoubleQuote:
+		return nil, fmt.Errorf("no closing quotation in %#v", s)
+	case shlexStateWord:
+		words = append(words, sb.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The expected values were obtained by running shlex.split in Python 3, which is what docker compose does.
+var shlexSplitTestCases = []struct {
+	input    string
+	expected []string
+}{
+	{"", []string{}},
+	{"  \t\n", []string{}},
+	{"npm run test -- --ci", []string{"npm", "run", "test", "--", "--ci"}},
+	{`bash -c "echo 'Hello World!'"`, []string{"bash", "-c", "echo 'Hello World!'"}},
+	{`echo "a\"b"`, []string{"echo", `a"b`}},
+	{`echo "a\\b"`, []string{"echo", `a\b`}},
+	{`echo "a\b"`, []string{"echo", `a\b`}},
+	{`echo 'a\b'`, []string{"echo", `a\b`}},
+	{`echo a\ b`, []string{"echo", "a b"}},
+	{`echo a\"b`, []string{"echo", `a"b`}},
+	{`a"b c"d`, []string{"ab cd"}},
+	{`echo '' ""`, []string{"echo", "", ""}},
+	{"echo $HOME #comment", []string{"echo", "$HOME", "#comment"}},
+	{"  leading   and trailing  ", []string{"leading", "and", "trailing"}},
+}
+
+func TestShlexSplit_Success(t *testing.T) {
+	for _, testCase := range shlexSplitTestCases {
+		actual, err := shlexSplit(testCase.input)
+		if err != nil {
+			t.Errorf("input %#v: %v", testCase.input, err)
+		} else if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("input %#v: expected %#v but got %#v", testCase.input, testCase.expected, actual)
+		}
+	}
+}
+
+func TestShlexSplit_Error(t *testing.T) {
+	inputs := []string{
+		`echo "unterminated`,
+		`echo 'unterminated`,
+		`echo \`,
+	}
+	for _, input := range inputs {
+		_, err := shlexSplit(input)
+		if err == nil {
+			t.Errorf("input %#v: expected an error", input)
+		}
+	}
+}
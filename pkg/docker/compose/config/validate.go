@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// The JSON schemas of the docker compose file format. The version 3 schemas are copied from github.com/docker/cli (cli/compose/schema/data)
+// and the Compose Specification schema is copied from github.com/compose-spec/compose-go/v2 (schema). The version 1 schema is copied from
+// github.com/docker/libcompose (config/schema.go), which copied it from docker compose. The version 2.0 schema consists of the definitions
+// of github.com/docker/libcompose, with the root of the version 2.0 schema of docker compose. All are licensed under the Apache License 2.0.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var (
+	compiledSchemasMutex sync.Mutex
+	compiledSchemas      = map[string]*jsonschema.Schema{}
+)
+
+// SchemaViolation is a part of a docker compose file that does not match the JSON schema of the docker compose file format.
+type SchemaViolation struct {
+	File string
+	// Line and Column are the 1-based position of the YAML node of the violation, and are 0 if the position is unknown.
+	Line   int
+	Column int
+	// Path is the dot separated path of the YAML node of the violation, and is empty for the root of the file.
+	Path    string
+	Message string
+}
+
+func (v *SchemaViolation) String() string {
+	var sb strings.Builder
+	sb.WriteString(v.File)
+	if v.Line > 0 {
+		fmt.Fprintf(&sb, ":%d:%d", v.Line, v.Column)
+	}
+	sb.WriteString(": ")
+	if v.Path != "" {
+		sb.WriteString(v.Path)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(v.Message)
+	return sb.String()
+}
+
+// SchemaError is returned when a docker compose file does not match the JSON schema of the docker compose file format. It reports all
+// violations of the file at once.
+type SchemaError struct {
+	File       string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "file %#v does not match the docker compose file schema:", e.File)
+	for i := 0; i < len(e.Violations); i++ {
+		sb.WriteString("\n  ")
+		sb.WriteString(e.Violations[i].String())
+	}
+	return sb.String()
+}
+
+// getSchemaFile returns the name of the JSON schema file for a docker compose file version. Versions 1, 2.0 and 3.0 to 3.9 have their own
+// schema. All other versions, and files that follow the Compose Specification, are validated against the schema of the Compose
+// Specification like docker compose v2 does, because the schemas of versions 2.1 to 2.4 are not published as part of a Go module.
+func getSchemaFile(v *version.Version) string {
+	if v != nil {
+		segments := v.Segments()
+		switch {
+		case segments[0] == 1:
+			return "config_schema_v1.json"
+		case segments[0] == 2 && segments[1] == 0:
+			return "config_schema_v2.0.json"
+		case segments[0] == 3 && segments[1] <= 9:
+			return fmt.Sprintf("config_schema_v3.%d.json", segments[1])
+		}
+	}
+	return "compose-spec.json"
+}
+
+func getCompiledSchema(schemaFile string) (*jsonschema.Schema, error) {
+	compiledSchemasMutex.Lock()
+	defer compiledSchemasMutex.Unlock()
+	if schema := compiledSchemas[schemaFile]; schema != nil {
+		return schema, nil
+	}
+	data, err := schemaFiles.ReadFile("schemas/" + schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	url := "file:///schemas/" + schemaFile
+	compiler := jsonschema.NewCompiler()
+	err = compiler.AddResource(url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, err
+	}
+	compiledSchemas[schemaFile] = schema
+	return schema, nil
+}
+
+// validateSchema validates an interpolated docker compose file against the JSON schema of its version. The positions of violations are
+// looked up in the YAML nodes of the file's contents. For version 1 dataMap must have the services at the key "services", like the
+// other versions.
+//
+// Unknown keys are not violations, because they are reported as ignored keys instead.
+func validateSchema(resolvedFile string, contents []byte, dataMap genericMap, v *version.Version) error {
+	schema, err := getCompiledSchema(getSchemaFile(v))
+	if err != nil {
+		return errors.Wrap(err, "error compiling docker compose file schema")
+	}
+	var data interface{} = dataMap
+	if isV1(v) {
+		// The services are the root of a version 1 file.
+		data = dataMap["services"]
+	}
+	err = schema.Validate(toJSONValue(data))
+	if err == nil {
+		return nil
+	}
+	validationError, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	var root *yamlv3.Node
+	var document yamlv3.Node
+	if yamlv3.Unmarshal(contents, &document) == nil && len(document.Content) > 0 {
+		root = document.Content[0]
+	}
+	schemaError := &SchemaError{
+		File: resolvedFile,
+	}
+	for _, leaf := range getSchemaViolationLeaves(validationError) {
+		path := splitJSONPointer(leaf.InstanceLocation)
+		violation := SchemaViolation{
+			File:    resolvedFile,
+			Path:    strings.Join(path, "."),
+			Message: leaf.Message,
+		}
+		if node := findYAMLNode(root, path); node != nil {
+			violation.Line = node.Line
+			violation.Column = node.Column
+		}
+		schemaError.Violations = append(schemaError.Violations, violation)
+	}
+	if len(schemaError.Violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(schemaError.Violations, func(i, j int) bool {
+		vi, vj := &schemaError.Violations[i], &schemaError.Violations[j]
+		if vi.Line != vj.Line {
+			return vi.Line < vj.Line
+		}
+		return vi.Column < vj.Column
+	})
+	return schemaError
+}
+
+// getSchemaViolationLeaves flattens the tree of a validation error into the errors that are reported to the user.
+// Failed oneOf and anyOf keywords are reported as a single type error if none of their alternatives have a matching type. Otherwise, the
+// alternative with a matching type is reported, because that is the alternative the user most likely intended.
+func getSchemaViolationLeaves(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if strings.HasSuffix(ve.KeywordLocation, "/oneOf") || strings.HasSuffix(ve.KeywordLocation, "/anyOf") {
+		var expectedTypes []string
+		var actualType string
+		for _, cause := range ve.Causes {
+			expectedType, ok := getTypeMismatch(cause, ve.InstanceLocation, &actualType)
+			if !ok {
+				return getSchemaViolationLeaves(cause)
+			}
+			expectedTypes = append(expectedTypes, expectedType)
+		}
+		if len(expectedTypes) > 0 {
+			leaf := *ve
+			leaf.Message = fmt.Sprintf("expected %s, but got %s", strings.Join(expectedTypes, " or "), actualType)
+			leaf.Causes = nil
+			return []*jsonschema.ValidationError{&leaf}
+		}
+	}
+	if len(ve.Causes) == 0 {
+		if strings.HasSuffix(ve.KeywordLocation, "/additionalProperties") {
+			return nil
+		}
+		return []*jsonschema.ValidationError{ve}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range ve.Causes {
+		leaves = append(leaves, getSchemaViolationLeaves(cause)...)
+	}
+	return leaves
+}
+
+// getTypeMismatch returns the expected type if a validation error is a type error of the value at instanceLocation. The actual type is
+// stored in actualType.
+func getTypeMismatch(ve *jsonschema.ValidationError, instanceLocation string, actualType *string) (string, bool) {
+	for len(ve.Causes) == 1 {
+		ve = ve.Causes[0]
+	}
+	if len(ve.Causes) > 0 || ve.InstanceLocation != instanceLocation || !strings.HasSuffix(ve.KeywordLocation, "/type") {
+		return "", false
+	}
+	// The message of a type error is "expected <types>, but got <type>".
+	parts := strings.SplitN(strings.TrimPrefix(ve.Message, "expected "), ", but got ", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	*actualType = parts[1]
+	return parts[0], true
+}
+
+func splitJSONPointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens
+}
+
+// findYAMLNode returns the node at path, or the deepest node on path if path does not exist. Aliases and merge keys are followed.
+func findYAMLNode(node *yamlv3.Node, path []string) *yamlv3.Node {
+	for _, token := range path {
+		if node == nil {
+			return nil
+		}
+		child := findYAMLChildNode(node, token)
+		if child == nil {
+			return node
+		}
+		node = child
+	}
+	return node
+}
+
+func findYAMLChildNode(node *yamlv3.Node, token string) *yamlv3.Node {
+	for node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yamlv3.SequenceNode:
+		i, err := strconv.Atoi(token)
+		if err == nil && 0 <= i && i < len(node.Content) {
+			return node.Content[i]
+		}
+	case yamlv3.MappingNode:
+		var merged []*yamlv3.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == token {
+				return value
+			}
+			if key.Tag == "!!merge" {
+				merged = append(merged, value)
+			}
+		}
+		for _, value := range merged {
+			for value.Kind == yamlv3.AliasNode {
+				value = value.Alias
+			}
+			mergedMaps := []*yamlv3.Node{value}
+			if value.Kind == yamlv3.SequenceNode {
+				mergedMaps = value.Content
+			}
+			for _, mergedMap := range mergedMaps {
+				if child := findYAMLChildNode(mergedMap, token); child != nil {
+					return child
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// toJSONValue converts a YAML decoded value to the types of JSON decoded values, so that it can be validated against a JSON schema.
+// Non-string keys are formatted as strings.
+func toJSONValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case genericMap:
+		return toJSONObject(t)
+	case map[interface{}]interface{}:
+		return toJSONObject(t)
+	case []interface{}:
+		a := make([]interface{}, len(t))
+		for i, item := range t {
+			a[i] = toJSONValue(item)
+		}
+		return a
+	case nil, bool, string, int, int64, uint64, float64:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func toJSONObject(m map[interface{}]interface{}) map[string]interface{} {
+	o := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		o[fmt.Sprint(key)] = toJSONValue(value)
+	}
+	return o
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func validateSchemaTest(t *testing.T, contents string) []SchemaViolation {
+	var dataMap genericMap
+	err := yaml.Unmarshal([]byte(contents), &dataMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := getVersion(dataMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Equal(v1) {
+		dataMap = genericMap{
+			"services": dataMap,
+		}
+	}
+	err = validateSchema("/docker-compose.yml", []byte(contents), dataMap, v)
+	if err == nil {
+		return nil
+	}
+	schemaError, ok := err.(*SchemaError)
+	if !ok {
+		t.Fatal(err)
+	}
+	return schemaError.Violations
+}
+
+func TestValidateSchema_Valid(t *testing.T) {
+	violations := validateSchemaTest(t, `version: '2.4'
+services:
+  service1:
+    image: ubuntu:latest
+    imagee: unknown keys are reported as ignored keys
+    ports:
+    - 8080:80
+    - 443
+x-kube-compose:
+  cluster_image_storage:
+    type: docker
+`)
+	if violations != nil {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_AllViolations(t *testing.T) {
+	violations := validateSchemaTest(t, `version: '2.4'
+services:
+  service1:
+    image: 1
+    ports:
+    - true
+  service2:
+    image: ubuntu:latest
+    healthcheck: yes
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 4, Column: 12, Path: "services.service1.image", Message: "expected string, but got number"},
+		{File: "/docker-compose.yml", Line: 6, Column: 7, Path: "services.service1.ports.0",
+			Message: "expected number or string or object, but got boolean"},
+		{File: "/docker-compose.yml", Line: 9, Column: 18, Path: "services.service2.healthcheck", Message: "expected object, but got boolean"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_V3(t *testing.T) {
+	violations := validateSchemaTest(t, `version: '3.9'
+services:
+  service1:
+    image: ubuntu:latest
+    volumes: /data
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 5, Column: 14, Path: "services.service1.volumes", Message: "expected array, but got string"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_V1(t *testing.T) {
+	violations := validateSchemaTest(t, `service1:
+  image: ubuntu:latest
+  environment: 1
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 3, Column: 16, Path: "service1.environment",
+			Message: "expected object or array, but got number"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_V1Schema(t *testing.T) {
+	// net only exists in version 1, so it is only validated by the version 1 schema.
+	violations := validateSchemaTest(t, `service1:
+  image: ubuntu:latest
+  net: 1
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 3, Column: 8, Path: "service1.net", Message: "expected string, but got number"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_V2_0(t *testing.T) {
+	// Conditions of depends_on were added in version 2.1.
+	violations := validateSchemaTest(t, `version: '2'
+services:
+  service1:
+    image: ubuntu:latest
+    depends_on:
+      service2:
+        condition: service_healthy
+  service2:
+    image: ubuntu:latest
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 6, Column: 7, Path: "services.service1.depends_on", Message: "expected array, but got object"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestValidateSchema_MergeKey(t *testing.T) {
+	violations := validateSchemaTest(t, `version: '2.4'
+x-defaults: &defaults
+  restart: [always]
+services:
+  service1:
+    <<: *defaults
+    image: ubuntu:latest
+`)
+	expected := []SchemaViolation{
+		{File: "/docker-compose.yml", Line: 3, Column: 12, Path: "services.service1.restart", Message: "expected string, but got array"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Error(violations)
+	}
+}
+
+func TestSchemaErrorString(t *testing.T) {
+	err := &SchemaError{
+		File: "/a.yml",
+		Violations: []SchemaViolation{
+			{File: "/a.yml", Line: 3, Column: 5, Path: "services", Message: "expected object, but got string"},
+			{File: "/a.yml", Message: "expected object, but got null"},
+		},
+	}
+	expected := "file \"/a.yml\" does not match the docker compose file schema:\n" +
+		"  /a.yml:3:5: services: expected object, but got string\n" +
+		"  /a.yml: expected object, but got null"
+	if s := err.Error(); s != expected {
+		t.Error(s)
+	}
+}
+
+func TestGetSchemaFile(t *testing.T) {
+	testCases := map[string]string{
+		"1":    "config_schema_v1.json",
+		"2":    "config_schema_v2.0.json",
+		"2.4":  "compose-spec.json",
+		"3":    "config_schema_v3.0.json",
+		"3.9":  "config_schema_v3.9.json",
+		"3.10": "compose-spec.json",
+	}
+	for vString, expected := range testCases {
+		if schemaFile := getSchemaFile(version.Must(version.NewVersion(vString))); schemaFile != expected {
+			t.Errorf("%s: %s", vString, schemaFile)
+		}
+	}
+}
+
+func TestFindYAMLNode_NotFound(t *testing.T) {
+	if node := findYAMLNode(nil, []string{"services"}); node != nil {
+		t.Fail()
+	}
+}
@@ -75,14 +75,15 @@ func volumeNameLength(s string) int {
 	return fsPackage.NTVolumeNameLength(s)
 }
 
-// Copy of the resolve_volume_path function:
+// resolveBindMountVolumeHostPath resolves relative host paths of bind mount volumes relative to dir, the directory that relative paths of
+// the docker compose file are relative to. Copy of the resolve_volume_path function:
 // https://github.com/docker/compose/blob/99e67d0c061fa3d9b9793391f3b7c8bdf8e841fc/compose/config/config.py#L1354
-func resolveBindMountVolumeHostPath(resolvedFile string, sv *ServiceVolume) {
+func resolveBindMountVolumeHostPath(dir string, sv *ServiceVolume) {
 	if sv.Short != nil && sv.Short.HasHostPath && sv.Short.HostPath != "" {
 		// The intent of the following if is to resolve relative file paths, but not all relative file paths start with a full stop. We
 		// still perform the check as follows, because docker compose also allows specifying named volumes.
 		if sv.Short.HostPath[0] == '.' {
-			sv.Short.HostPath = expandPath(resolvedFile, sv.Short.HostPath)
+			sv.Short.HostPath = expandPath(dir, sv.Short.HostPath)
 		} else {
 			sv.Short.HostPath = expanduser.ExpandUser(sv.Short.HostPath)
 		}
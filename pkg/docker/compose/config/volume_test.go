@@ -83,7 +83,7 @@ func TestResolveBindMountVolumeHostPath_Success(t *testing.T) {
 			HostPath:    "./Documents",
 		},
 	}
-	resolveBindMountVolumeHostPath("/Users/henk/.bash_profile", &sv)
+	resolveBindMountVolumeHostPath("/Users/henk", &sv)
 	expected := ServiceVolume{
 		Short: &PathMapping{
 			HasHostPath: true,
@@ -109,5 +109,5 @@ func TestResolveBindMountVolumeHostPath_TildeNotSupported(t *testing.T) {
 		}
 		return "", false
 	}
-	resolveBindMountVolumeHostPath("/Users/henk/.bash_profile", &sv)
+	resolveBindMountVolumeHostPath("/Users/henk", &sv)
 }
@@ -0,0 +1,295 @@
+// Package kubecompose is the Go API of kube-compose. It loads docker compose files and runs them on a Kubernetes cluster, like the
+// commands of kube-compose. Unlike the commands, it does not read flags, environment variables or the kube config file, and the caller
+// can provide the Kubernetes and docker clients and handle warnings and progress instead of having them printed.
+package kubecompose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/kube-compose/kube-compose/internal/app/config"
+	"github.com/kube-compose/kube-compose/internal/app/down"
+	details "github.com/kube-compose/kube-compose/internal/app/get"
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	"github.com/kube-compose/kube-compose/internal/app/up"
+	dockerComposeConfig "github.com/kube-compose/kube-compose/pkg/docker/compose/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultNamespace is the namespace of a project if LoadOptions does not set a namespace.
+const DefaultNamespace = "default"
+
+// Event is a warning, progress or a line of the logs of a container that kube-compose reports.
+type Event = config.Event
+
+// EventType is the type of an Event.
+type EventType = config.EventType
+
+const (
+	// EventInfo reports progress, for example that a pod was created.
+	EventInfo = config.EventInfo
+	// EventWarning reports that (part of) the configuration is ignored or not fully supported.
+	EventWarning = config.EventWarning
+	// EventLog is a line of the logs of a container. The Service of the event is the docker compose service of the container.
+	EventLog = config.EventLog
+)
+
+// EventHandler handles events. It can be called concurrently by multiple goroutines. The nil EventHandler prints each event to standard
+// output, like the commands of kube-compose.
+type EventHandler = config.EventHandler
+
+// DockerClient is the part of the docker API that Up uses. It is implemented by the client of github.com/docker/docker/client.
+type DockerClient = up.DockerClient
+
+// ServiceDetails are the details of the Kubernetes service of a docker compose service.
+type ServiceDetails = details.ServiceDetails
+
+// LoadOptions configure Load.
+type LoadOptions struct {
+	// EnvironmentID is the ID of the environment, which is part of the names and labels of all Kubernetes resources, so that multiple
+	// environments can share a namespace. It is required and must be a valid label value.
+	EnvironmentID string
+	// EventHandler handles the events of Load, and of Up and Down with the loaded project.
+	EventHandler EventHandler
+	// KubeConfig is used to create a Kubernetes client if the options of Up, Down or Get have no Kubernetes client. Its bearer token also
+	// authenticates pushes of images to the docker registry of the cluster. KubeConfig can be nil.
+	KubeConfig *rest.Config
+	// Namespace is the namespace of the Kubernetes resources. It must be empty if each environment has its own namespace, and is
+	// DefaultNamespace if it is empty otherwise.
+	Namespace string
+	// NamespacePerEnvironment is true to give each environment its own namespace, even if the docker compose files do not configure so.
+	NamespacePerEnvironment bool
+	// ClusterDomain is the DNS domain of the Kubernetes cluster, and overrides the docker compose files if it is not empty. The default is
+	// "cluster.local".
+	ClusterDomain string
+	// AppLabel, EnvironmentLabel and NameTemplate override the naming scheme of the docker compose files if they are not empty.
+	AppLabel         string
+	EnvironmentLabel string
+	NameTemplate     string
+	// Profiles are the active profiles.
+	Profiles []string
+	// ProjectDirectory is the directory that relative paths of the docker compose files are resolved against. If it is empty then the
+	// directory of each file is used.
+	ProjectDirectory string
+	// Services are the names of the docker compose services that Up and Down act on, in addition to their dependencies. If
+	// Services is empty then the services that are enabled by the active profiles are used.
+	Services []string
+	// Stdin is read if one of the files is "-". If it is nil then os.Stdin is used.
+	Stdin io.Reader
+	// Strict is true to return an error instead of warnings if the docker compose files have keys that are ignored.
+	Strict bool
+}
+
+// Project is a loaded docker compose configuration. A Project must not be used by multiple goroutines concurrently.
+type Project struct {
+	cfg *config.Config
+}
+
+// Load loads and merges docker compose files, like the -f flags of kube-compose. If files is empty then the standard docker compose
+// files of the current working directory are loaded. opts can be nil, but an EnvironmentID is required.
+func Load(files []string, opts *LoadOptions) (*Project, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	if opts.EnvironmentID == "" {
+		return nil, fmt.Errorf("an environment ID is required")
+	}
+	if e := validation.IsValidLabelValue(opts.EnvironmentID); len(e) > 0 {
+		return nil, fmt.Errorf("the environment ID must be a valid label value: %s", e[0])
+	}
+	cfg, err := config.NewFromFiles(files, &config.Options{
+		Options: dockerComposeConfig.Options{
+			ProjectDirectory: opts.ProjectDirectory,
+			Stdin:            opts.Stdin,
+		},
+		EventHandler:     opts.EventHandler,
+		AppLabel:         optionalString(opts.AppLabel),
+		EnvironmentLabel: optionalString(opts.EnvironmentLabel),
+		NameTemplate:     optionalString(opts.NameTemplate),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Strict {
+		cfg.Strict = true
+	}
+	if err = cfg.CheckIgnoredKeys(); err != nil {
+		return nil, err
+	}
+	cfg.EnvironmentID = opts.EnvironmentID
+	cfg.KubeConfig = opts.KubeConfig
+	if opts.ClusterDomain != "" {
+		if err = cfg.SetClusterDomain(opts.ClusterDomain); err != nil {
+			return nil, err
+		}
+	}
+	if err = setNamespace(cfg, opts); err != nil {
+		return nil, err
+	}
+	if err = k8smeta.ValidateK8sNames(cfg); err != nil {
+		return nil, err
+	}
+	cfg.Profiles = append(cfg.Profiles, opts.Profiles...)
+	if err = cfg.SetFilter(opts.Services); err != nil {
+		return nil, err
+	}
+	return &Project{
+		cfg: cfg,
+	}, nil
+}
+
+// optionalString returns nil if s is empty, and a pointer to s otherwise.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func setNamespace(cfg *config.Config, opts *LoadOptions) error {
+	if opts.NamespacePerEnvironment {
+		cfg.NamespacePerEnvironment = true
+	}
+	if !cfg.NamespacePerEnvironment {
+		cfg.Namespace = opts.Namespace
+		if cfg.Namespace == "" {
+			cfg.Namespace = DefaultNamespace
+		}
+		return nil
+	}
+	if opts.Namespace != "" {
+		return fmt.Errorf("the namespace cannot be set if each environment has its own namespace")
+	}
+	cfg.Namespace = k8smeta.GetEnvironmentNamespace(cfg)
+	return nil
+}
+
+// Namespace returns the namespace of the Kubernetes resources of the project.
+func (p *Project) Namespace() string {
+	return p.cfg.Namespace
+}
+
+// Services returns the sorted names of the docker compose services that Up and Down act on (see LoadOptions.Services).
+func (p *Project) Services() []string {
+	var names []string
+	for _, service := range p.cfg.Services {
+		if p.cfg.MatchesFilter(service) {
+			names = append(names, service.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IgnoredKeys returns the keys of the docker compose files that are ignored by kube-compose.
+func (p *Project) IgnoredKeys() []dockerComposeConfig.IgnoredKey {
+	return p.cfg.IgnoredKeys
+}
+
+// UpOptions configure Up.
+type UpOptions struct {
+	// Detach is true to return once all pods are ready (or have completed), instead of streaming their logs until they terminate.
+	Detach bool
+	// DockerClient is the client of the docker daemon that has the images of the docker compose services. If it is nil then a client is
+	// created from the environment variables DOCKER_HOST, DOCKER_API_VERSION, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY.
+	DockerClient DockerClient
+	// ForceRecreate is true to recreate existing pods even if their spec did not change.
+	ForceRecreate bool
+	// KubernetesClient is the client of the Kubernetes cluster. If it is nil then a client is created from LoadOptions.KubeConfig.
+	KubernetesClient kubernetes.Interface
+	// NoRecreate is true to never recreate existing pods, even if their spec changed.
+	NoRecreate bool
+	// RemoveOrphans is true to delete pods and services of docker compose services that are no longer in the docker compose files.
+	RemoveOrphans bool
+	// RunAsUser is true to set runAsUser/runAsGroup of each pod based on the user of the pod's image and the "user" key of the pod's
+	// docker compose service.
+	RunAsUser bool
+}
+
+// Up creates the Kubernetes services and pods of the project in an order that respects depends_on, like kube-compose up. ctx cancels the
+// requests of the docker client, waiting for cluster IPs and pods, and streaming the logs of pods. opts can be nil.
+func Up(ctx context.Context, p *Project, opts *UpOptions) error {
+	if opts == nil {
+		opts = &UpOptions{}
+	}
+	if opts.ForceRecreate && opts.NoRecreate {
+		return fmt.Errorf("ForceRecreate and NoRecreate are incompatible")
+	}
+	if err := p.checkKubernetesClient(opts.KubernetesClient); err != nil {
+		return err
+	}
+	return up.Run(p.cfg, &up.Options{
+		Context:          ctx,
+		Detach:           opts.Detach,
+		DockerClient:     opts.DockerClient,
+		ForceRecreate:    opts.ForceRecreate,
+		KubernetesClient: opts.KubernetesClient,
+		NoRecreate:       opts.NoRecreate,
+		RemoveOrphans:    opts.RemoveOrphans,
+		RunAsUser:        opts.RunAsUser,
+	})
+}
+
+// DownOptions configure Down.
+type DownOptions struct {
+	// KeepNamespace is true to keep the namespace of the environment if each environment has its own namespace.
+	KeepNamespace bool
+	// KubernetesClient is the client of the Kubernetes cluster. If it is nil then a client is created from LoadOptions.KubeConfig.
+	KubernetesClient kubernetes.Interface
+	// RemoveOrphans is true to delete pods and services of docker compose services that are no longer in the docker compose files.
+	RemoveOrphans bool
+	// Timeout is the maximum duration to wait for pods or the namespace to be deleted. Zero means down.DefaultTimeout (5 minutes).
+	Timeout time.Duration
+}
+
+// Down deletes the Kubernetes services and pods of the project, like kube-compose down. Down stops waiting for pods or the namespace to be
+// deleted when ctx is done. opts can be nil.
+func Down(ctx context.Context, p *Project, opts *DownOptions) error {
+	if opts == nil {
+		opts = &DownOptions{}
+	}
+	if err := p.checkKubernetesClient(opts.KubernetesClient); err != nil {
+		return err
+	}
+	return down.Run(p.cfg, &down.Options{
+		Context:          ctx,
+		KeepNamespace:    opts.KeepNamespace,
+		KubernetesClient: opts.KubernetesClient,
+		RemoveOrphans:    opts.RemoveOrphans,
+		Timeout:          opts.Timeout,
+	})
+}
+
+// GetOptions configure Get.
+type GetOptions struct {
+	// KubernetesClient is the client of the Kubernetes cluster. If it is nil then a client is created from LoadOptions.KubeConfig.
+	KubernetesClient kubernetes.Interface
+}
+
+// Get gets the details of the Kubernetes service of a docker compose service of the project, like kube-compose get. Get returns the error
+// of ctx if ctx is done before the Kubernetes service is retrieved. opts can be nil.
+func Get(ctx context.Context, p *Project, service string, opts *GetOptions) (*ServiceDetails, error) {
+	if opts == nil {
+		opts = &GetOptions{}
+	}
+	if err := p.checkKubernetesClient(opts.KubernetesClient); err != nil {
+		return nil, err
+	}
+	s := p.cfg.FindServiceByName(service)
+	if s == nil {
+		return nil, fmt.Errorf("no service named %#v exists", service)
+	}
+	return details.GetServiceDetails(ctx, p.cfg, s, opts.KubernetesClient)
+}
+
+func (p *Project) checkKubernetesClient(k8sClient kubernetes.Interface) error {
+	if k8sClient == nil && p.cfg.KubeConfig == nil {
+		return fmt.Errorf("either a Kubernetes client or LoadOptions.KubeConfig is required")
+	}
+	return nil
+}
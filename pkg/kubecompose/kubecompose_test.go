@@ -0,0 +1,216 @@
+package kubecompose
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kube-compose/kube-compose/internal/app/k8smeta"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testComposeFile = `version: '2.4'
+services:
+  a:
+    image: ubuntu:latest
+    depends_on:
+    - b
+    tmpfs: /tmp
+  b:
+    image: ubuntu:latest
+  c:
+    image: ubuntu:latest
+    profiles: [debug]
+`
+
+func newTestProject(t *testing.T, opts *LoadOptions) *Project {
+	opts.Stdin = strings.NewReader(testComposeFile)
+	if opts.EnvironmentID == "" {
+		opts.EnvironmentID = "myenv"
+	}
+	p, err := Load([]string{"-"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoad_Success(t *testing.T) {
+	var events []*Event
+	p := newTestProject(t, &LoadOptions{
+		EventHandler: func(event *Event) {
+			events = append(events, event)
+		},
+	})
+	if services := p.Services(); !reflect.DeepEqual(services, []string{"a", "b"}) {
+		t.Error(services)
+	}
+	if p.Namespace() != DefaultNamespace {
+		t.Error(p.Namespace())
+	}
+	if len(p.IgnoredKeys()) != 1 {
+		t.Error(p.IgnoredKeys())
+	}
+	if len(events) != 1 || events[0].Type != EventWarning {
+		t.Error(events)
+	}
+}
+
+func TestLoad_ServicesAndProfiles(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{
+		Profiles: []string{"debug"},
+	})
+	if services := p.Services(); !reflect.DeepEqual(services, []string{"a", "b", "c"}) {
+		t.Error(services)
+	}
+	p = newTestProject(t, &LoadOptions{
+		Services: []string{"a"},
+	})
+	if services := p.Services(); !reflect.DeepEqual(services, []string{"a", "b"}) {
+		t.Error(services)
+	}
+}
+
+func TestLoad_NamespacePerEnvironment(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{
+		NamespacePerEnvironment: true,
+	})
+	if p.Namespace() == DefaultNamespace {
+		t.Error(p.Namespace())
+	}
+	_, err := Load([]string{"-"}, &LoadOptions{
+		EnvironmentID:           "myenv",
+		Namespace:               "mynamespace",
+		NamespacePerEnvironment: true,
+		Stdin:                   strings.NewReader(testComposeFile),
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	testCases := map[string]*LoadOptions{
+		"no environment ID":      nil,
+		"invalid environment ID": {EnvironmentID: "my env"},
+		"strict":                 {EnvironmentID: "myenv", Strict: true},
+		"unknown service":        {EnvironmentID: "myenv", Services: []string{"d"}},
+		"invalid app label":      {EnvironmentID: "myenv", AppLabel: "-"},
+	}
+	for name, opts := range testCases {
+		if opts != nil {
+			opts.Stdin = strings.NewReader(testComposeFile)
+		}
+		if _, err := Load([]string{"-"}, opts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestUpDownGet_NoKubernetesClient(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{})
+	if err := Up(context.Background(), p, nil); err == nil {
+		t.Fail()
+	}
+	if err := Down(context.Background(), p, nil); err == nil {
+		t.Fail()
+	}
+	if _, err := Get(context.Background(), p, "a", nil); err == nil {
+		t.Fail()
+	}
+}
+
+func TestUp_IncompatibleOptions(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{})
+	err := Up(context.Background(), p, &UpOptions{
+		ForceRecreate: true,
+		NoRecreate:    true,
+	})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+// newTestObjects returns the anchor and the pods and services of the project, as if they were created by Up.
+func newTestObjects(p *Project) []runtime.Object {
+	anchor := &v1.ConfigMap{}
+	k8smeta.InitAnchorObjectMeta(p.cfg, &anchor.ObjectMeta)
+	anchor.Namespace = p.Namespace()
+	anchor.UID = "anchor-uid"
+	objects := []runtime.Object{anchor}
+	for _, name := range p.Services() {
+		composeService := p.cfg.FindServiceByName(name)
+		objectMeta := metav1.ObjectMeta{
+			Namespace: p.Namespace(),
+			OwnerReferences: []metav1.OwnerReference{
+				k8smeta.NewAnchorOwnerReference(&anchor.ObjectMeta),
+			},
+		}
+		k8smeta.InitObjectMeta(p.cfg, &objectMeta, composeService)
+		objects = append(objects, &v1.Pod{
+			ObjectMeta: objectMeta,
+		}, &v1.Service{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			Spec: v1.ServiceSpec{
+				ClusterIP: "10.0.0.1",
+			},
+		})
+	}
+	return objects
+}
+
+func TestDown_FakeKubernetesClient(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{})
+	k8sClient := fake.NewSimpleClientset(newTestObjects(p)...)
+	err := Down(context.Background(), p, &DownOptions{
+		KubernetesClient: k8sClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods, err := k8sClient.CoreV1().Pods(p.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, err := k8sClient.CoreV1().Services(p.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMaps, err := k8sClient.CoreV1().ConfigMaps(p.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 || len(services.Items) != 0 || len(configMaps.Items) != 0 {
+		t.Error(pods.Items, services.Items, configMaps.Items)
+	}
+}
+
+func TestGet_FakeKubernetesClient(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{})
+	opts := &GetOptions{
+		KubernetesClient: fake.NewSimpleClientset(newTestObjects(p)...),
+	}
+	d, err := Get(context.Background(), p, "a", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "a" || d.ClusterIP != "10.0.0.1" || d.Hostname != k8smeta.GetServiceFQDN(p.cfg, "a-myenv") {
+		t.Error(d)
+	}
+}
+
+func TestGet_ContextDone(t *testing.T) {
+	p := newTestProject(t, &LoadOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Get(ctx, p, "a", &GetOptions{
+		KubernetesClient: fake.NewSimpleClientset(newTestObjects(p)...),
+	})
+	if err != context.Canceled {
+		t.Error(err)
+	}
+}